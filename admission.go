@@ -0,0 +1,153 @@
+package websocket
+
+import (
+	"errors"
+	pb "github.com/bin-x/websocket/proto"
+	"github.com/gorilla/websocket"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// 同一uid的连接数达到上限时的处理方式
+type UidLimitPolicy string
+
+const (
+	// 拒绝新的连接
+	UidLimitRejectNew UidLimitPolicy = "reject_new"
+	// 关闭最早的连接
+	UidLimitKickOldest UidLimitPolicy = "kick_oldest"
+)
+
+var ErrUidLimitExceeded = errors.New("uid connection limit exceeded")
+
+// 连接数限制，0为不限制
+type AdmissionLimits struct {
+	// 本服务的最大连接数，超出时返回503
+	MaxConnections int
+	// 本服务每个ip的最大连接数，超出时返回429
+	MaxConnectionsPerIp int
+	// 整个集群每个uid的最大连接数，在BindUid时检查
+	MaxConnectionsPerUid int
+	UidLimitPolicy       UidLimitPolicy
+	// 在升级连接前从请求中获取uid，返回非空时检查uid限制，超出时返回429，连接后自动绑定该uid
+	UidFromRequest func(r *http.Request) string
+}
+
+// 开启连接数限制，uid限制需要所有服务使用相同的配置
+func WithAdmissionLimits(limits AdmissionLimits) ServiceOption {
+	return func(sh *ServiceHub) {
+		sh.admission = &admission{limits: limits, ips: make(map[string]int), uids: make(map[string]*uidLock)}
+	}
+}
+
+type admission struct {
+	mu     sync.Mutex
+	limits AdmissionLimits
+	total  int
+	ips    map[string]int
+	// 正在检查和绑定的uid
+	uids map[string]*uidLock
+}
+
+type uidLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// 占用一个连接名额，失败时返回http状态码
+func (a *admission) acquire(ip string) int {
+	if a == nil {
+		return 0
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.limits.MaxConnections > 0 && a.total >= a.limits.MaxConnections {
+		return http.StatusServiceUnavailable
+	}
+	if a.limits.MaxConnectionsPerIp > 0 && a.ips[ip] >= a.limits.MaxConnectionsPerIp {
+		return http.StatusTooManyRequests
+	}
+	a.total++
+	a.ips[ip]++
+	return 0
+}
+
+func (a *admission) release(ip string) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.total--
+	if a.ips[ip]--; a.ips[ip] <= 0 {
+		delete(a.ips, ip)
+	}
+}
+
+// 同一uid在本服务上的检查和绑定依次进行，避免同时连接时都通过检查，返回解锁的函数。
+// 未开启uid限制时不加锁。
+func (a *admission) lockUid(uid string) func() {
+	if a == nil || a.limits.MaxConnectionsPerUid <= 0 {
+		return func() {}
+	}
+	a.mu.Lock()
+	l, ok := a.uids[uid]
+	if !ok {
+		l = &uidLock{}
+		a.uids[uid] = l
+	}
+	l.refs++
+	a.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		a.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(a.uids, uid)
+		}
+		a.mu.Unlock()
+	}
+}
+
+func requestIp(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// 检查clientId绑定uid后是否超出限制，kick_oldest时关闭多出的最早的连接。
+// 需要在lockUid后调用，直到绑定完成。
+func (s *ServiceApi) admitUid(clientId, uid string) error {
+	a := s.hub.admission
+	if a == nil || a.limits.MaxConnectionsPerUid <= 0 {
+		return nil
+	}
+	var sessions []*pb.Client
+	for _, session := range s.GetSessionsByUid(uid) {
+		if session.Id != clientId {
+			sessions = append(sessions, session)
+		}
+	}
+	over := len(sessions) - a.limits.MaxConnectionsPerUid + 1
+	if over <= 0 {
+		return nil
+	}
+	if a.limits.UidLimitPolicy != UidLimitKickOldest {
+		return ErrUidLimitExceeded
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		if sessions[i].ConnectTime != sessions[j].ConnectTime {
+			return sessions[i].ConnectTime < sessions[j].ConnectTime
+		}
+		return sessions[i].Id < sessions[j].Id
+	})
+	for _, session := range sessions[:over] {
+		s.CloseClientWithReason(session.Id, websocket.ClosePolicyViolation, "too many connections for uid")
+	}
+	return nil
+}
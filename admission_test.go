@@ -0,0 +1,82 @@
+package websocket
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAdmission_acquire(t *testing.T) {
+	t.Parallel()
+	a := &admission{limits: AdmissionLimits{MaxConnections: 3, MaxConnectionsPerIp: 2}, ips: make(map[string]int)}
+	tests := []struct {
+		name string
+		ip   string
+		want int
+	}{
+		{"first", "1.1.1.1", 0},
+		{"second from same ip", "1.1.1.1", 0},
+		{"over ip limit", "1.1.1.1", http.StatusTooManyRequests},
+		{"other ip", "2.2.2.2", 0},
+		{"over node limit", "3.3.3.3", http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		if got := a.acquire(tt.ip); got != tt.want {
+			t.Errorf("%v acquire() got = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	a.release("1.1.1.1")
+	if got := a.acquire("1.1.1.1"); got != 0 {
+		t.Errorf("acquire() after release got = %v, want 0", got)
+	}
+	var none *admission
+	if got := none.acquire("1.1.1.1"); got != 0 {
+		t.Errorf("nil admission acquire() got = %v, want 0", got)
+	}
+}
+
+func TestServeWs_originBeforeAdmission(t *testing.T) {
+	t.Parallel()
+	hub := CreateHub()
+	hub.origin = newOriginChecker(OriginPolicy{})
+	hub.admission = &admission{limits: AdmissionLimits{MaxConnections: 1}, ips: make(map[string]int)}
+
+	r := httptest.NewRequest(http.MethodGet, "http://example.com/ws", nil)
+	r.Header.Set("Origin", "https://evil.com")
+	w := httptest.NewRecorder()
+	ServeWs(hub, w, r)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("ServeWs() status got = %v, want 403", w.Code)
+	}
+	if hub.admission.total != 0 || hub.OriginRejections() != 1 {
+		t.Errorf("ServeWs() got admission total = %v, rejections = %v, want 0 and 1", hub.admission.total, hub.OriginRejections())
+	}
+}
+
+func TestAdmission_lockUid(t *testing.T) {
+	t.Parallel()
+	a := &admission{limits: AdmissionLimits{MaxConnectionsPerUid: 1}, ips: make(map[string]int), uids: make(map[string]*uidLock)}
+	unlock := a.lockUid(uid1)
+
+	// 同一uid等待前一个绑定完成，其他uid不受影响
+	locked := make(chan func())
+	go func() { locked <- a.lockUid(uid1) }()
+	a.lockUid(uid2)()
+	select {
+	case <-locked:
+		t.Fatalf("lockUid() for the same uid didn't wait")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	(<-locked)()
+	if len(a.uids) != 0 {
+		t.Errorf("uids after unlock got = %v, want empty", len(a.uids))
+	}
+
+	// 未开启uid限制时不加锁
+	var none *admission
+	none.lockUid(uid1)()
+	none.lockUid(uid1)()
+}
@@ -4,9 +4,10 @@ import (
 	"context"
 	"errors"
 	pb "github.com/bin-x/websocket/proto"
-	"log"
+	"github.com/gorilla/websocket"
 	"reflect"
 	"strconv"
+	"time"
 )
 
 var Api *ServiceApi
@@ -17,40 +18,53 @@ type ServiceApi struct {
 
 // 判断是否为本地服务，如果为本地服务则不使用rpc
 func (s *ServiceApi) isLocal(addr string) bool {
-	localAddr := s.hub.lanIp + ":" + strconv.FormatUint(uint64(s.hub.rpcPort), 10)
-	return localAddr == addr
+	return s.hub.rpcAddr() == addr
 }
 
 // 调用分布式系统中的服务，并将返回结果合并
 func (s *ServiceApi) call(method string, ctx context.Context, request *pb.ServiceRequest) ([]*pb.ServiceResponse, error) {
-	//log.Println("call", method)
 	var responses []*pb.ServiceResponse
 	for addr, _ := range s.hub.otherAddress {
-		// 本地服务则直接调用，减少rpc的开销
-		if s.isLocal(addr) {
-			response, err := call(s.hub.rm, method, ctx, request)
-			if err != nil {
-				log.Println("call local method error:", err)
-				continue
-			}
-			responses = append(responses, response)
-			continue
-		}
-
-		client, err := s.hub.getServiceConn(addr)
+		response, err := s.callNode(addr, method, ctx, request)
 		if err != nil {
-			log.Println("get service conn error:", err)
+			s.hub.log(LogWarn, "call method error", F("addr", addr), F("method", method), F("error", err))
 			continue
 		}
-		c := pb.NewServiceApiClient(client.conn)
-		response, err := call(c, method, ctx, request)
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// 调用某个服务
+func (s *ServiceApi) callNode(addr string, method string, ctx context.Context, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
+	start := time.Now()
+	// 本地服务则直接调用，减少rpc的开销
+	info := &CallInfo{Node: addr, Method: method, Local: s.isLocal(addr)}
+	response, err := chainCall(s.hub.callInterceptors, s.hub.invoke)(ctx, info, request)
+	s.hub.metrics.observeCall(addr, method, time.Since(start), err)
+	return response, err
+}
+
+// clientId所在服务的rpc地址
+func nodeOfClientId(clientId string) (string, error) {
+	ip, port, _, err := ClientIdToAddress(clientId)
+	if err != nil {
+		return "", err
+	}
+	return ip + ":" + strconv.FormatUint(uint64(port), 10), nil
+}
+
+// 按clientId所在的服务对clientId进行分组
+func groupClientIdsByNode(clientIds []string) map[string][]string {
+	nodes := make(map[string][]string)
+	for _, clientId := range clientIds {
+		addr, err := nodeOfClientId(clientId)
 		if err != nil {
-			log.Println("call remote method error:", err)
 			continue
 		}
-		responses = append(responses, response)
+		nodes[addr] = append(nodes[addr], clientId)
 	}
-	return responses, nil
+	return nodes
 }
 
 // 方法调用
@@ -71,6 +85,9 @@ func call(value interface{}, method string, ctx context.Context, request *pb.Ser
 	if len(out) != 2 {
 		return nil, errors.New("call error")
 	}
+	if err, ok := out[1].Interface().(error); ok && err != nil {
+		return nil, err
+	}
 	response, ok := out[0].Interface().(*pb.ServiceResponse)
 	if !ok {
 		return nil, errors.New("call error")
@@ -79,28 +96,88 @@ func call(value interface{}, method string, ctx context.Context, request *pb.Ser
 }
 
 // 发送消息给所有客户端
-func (s *ServiceApi) SendToAll(message []byte) {
-	s.call("SendToAll", context.Background(), &pb.ServiceRequest{Message: message})
+func (s *ServiceApi) SendToAll(message []byte, options ...SendOption) {
+	request := &pb.ServiceRequest{Message: message}
+	applySendOptions(request, options)
+	s.call("SendToAll", context.Background(), request)
 }
 
 // 发送消息给某个客户端
-func (s *ServiceApi) SendToClient(clientId string, message []byte) {
-	s.call("SendToClient", context.Background(), &pb.ServiceRequest{Message: message, ClientId: clientId})
+func (s *ServiceApi) SendToClient(clientId string, message []byte, options ...SendOption) {
+	request := &pb.ServiceRequest{Message: message, ClientId: clientId}
+	applySendOptions(request, options)
+	s.call("SendToClient", context.Background(), request)
 }
 
-// 发送消息给某个uid
-func (s *ServiceApi) SendToUid(uid string, message []byte) {
-	s.call("SendToUid", context.Background(), &pb.ServiceRequest{Message: message, Uid: uid})
+// 发送消息给某个uid，uid不在线时保存到离线消息
+func (s *ServiceApi) SendToUid(uid string, message []byte, options ...SendOption) DeliveryState {
+	request := &pb.ServiceRequest{Message: message, Uid: uid}
+	applySendOptions(request, options)
+	responses, _ := s.call("SendToUid", context.Background(), request)
+	for _, response := range responses {
+		if response.Result {
+			return DeliveryDelivered
+		}
+	}
+
+	if s.hub.messageStore == nil {
+		return DeliveryDropped
+	}
+	if err := s.hub.messageStore.Push(uid, message); err != nil {
+		s.hub.log(LogError, "push offline message error", F("uid", uid), F("error", err))
+		return DeliveryDropped
+	}
+	return DeliveryStored
 }
 
 // 发送消息给某个分组
-func (s *ServiceApi) SendToGroup(group string, message []byte) {
-	s.call("SendToGroup", context.Background(), &pb.ServiceRequest{Message: message, Group: group})
+func (s *ServiceApi) SendToGroup(group string, message []byte, options ...SendOption) {
+	request := &pb.ServiceRequest{Message: message, Group: group}
+	applySendOptions(request, options)
+	s.call("SendToGroup", context.Background(), request)
+}
+
+// 发送消息给多个客户端，每个服务只调用一次
+func (s *ServiceApi) SendToClients(clientIds []string, message []byte, options ...SendOption) {
+	for addr, ids := range groupClientIdsByNode(clientIds) {
+		request := &pb.ServiceRequest{Message: message, ClientIds: ids}
+		applySendOptions(request, options)
+		_, err := s.callNode(addr, "SendToClients", context.Background(), request)
+		if err != nil {
+			s.hub.log(LogWarn, "call method error", F("addr", addr), F("method", "SendToClients"), F("error", err))
+		}
+	}
+}
+
+// 发送消息给多个uid，同一个client只会收到一次
+func (s *ServiceApi) SendToUids(uids []string, message []byte, options ...SendOption) {
+	request := &pb.ServiceRequest{Message: message, Uids: uids}
+	applySendOptions(request, options)
+	s.call("SendToUids", context.Background(), request)
+}
+
+// 发送消息给多个分组，同一个client只会收到一次
+func (s *ServiceApi) SendToGroups(groups []string, message []byte, options ...SendOption) {
+	request := &pb.ServiceRequest{Message: message, Groups: groups}
+	applySendOptions(request, options)
+	s.call("SendToGroups", context.Background(), request)
 }
 
 // 绑定uid
-func (s *ServiceApi) BindUid(clientId, uid string) {
-	s.call("BindUid", context.Background(), &pb.ServiceRequest{ClientId: clientId, Uid: uid})
+// 配置了uid连接数限制时，超出限制返回ErrUidLimitExceeded或关闭该uid最早的连接
+func (s *ServiceApi) BindUid(clientId, uid string) error {
+	unlock := s.hub.admission.lockUid(uid)
+	defer unlock()
+	return s.bindUid(clientId, uid)
+}
+
+// 检查uid限制后绑定，调用前需要lockUid
+func (s *ServiceApi) bindUid(clientId, uid string) error {
+	if err := s.admitUid(clientId, uid); err != nil {
+		return err
+	}
+	_, err := s.call("BindUid", context.Background(), &pb.ServiceRequest{ClientId: clientId, Uid: uid})
+	return err
 }
 
 // 解绑uid
@@ -108,6 +185,22 @@ func (s *ServiceApi) UnbindUid(clientId string) {
 	s.call("UnbindUid", context.Background(), &pb.ServiceRequest{ClientId: clientId})
 }
 
+// 获取某个uid离线消息的投递状态，合并所有服务的统计
+func (s *ServiceApi) GetOfflineMessageStats(uid string) MessageStoreStats {
+	var stats MessageStoreStats
+	responses, _ := s.call("GetOfflineMessageStats", context.Background(), &pb.ServiceRequest{Uid: uid})
+	for _, response := range responses {
+		if response.StoreStats == nil {
+			continue
+		}
+		stats.Pending += response.StoreStats.Pending
+		stats.Delivered += response.StoreStats.Delivered
+		stats.Expired += response.StoreStats.Expired
+		stats.Dropped += response.StoreStats.Dropped
+	}
+	return stats
+}
+
 // 判断某个uid是否在线
 func (s *ServiceApi) IsUidOnline(uid string) bool {
 	responses, _ := s.call("IsUidOnline", context.Background(), &pb.ServiceRequest{Uid: uid})
@@ -139,13 +232,65 @@ func (s *ServiceApi) GetClientIdsByUid(uid string) []string {
 	return clientIds
 }
 
-func (s *ServiceApi) JoinGroup(clientId, group string) {
-	s.call("JoinGroup", context.Background(), &pb.ServiceRequest{ClientId: clientId, Group: group})
+// 加入分组，传入ReplayOption时将分组的历史消息发送给该client，如 JoinGroup(clientId, group, ReplayLast(20))
+func (s *ServiceApi) JoinGroup(clientId, group string, replay ...ReplayOption) {
+	request := &pb.ServiceRequest{ClientId: clientId, Group: group}
+	if len(replay) > 0 {
+		request.Replay = newReplay(replay)
+	}
+	s.call("JoinGroup", context.Background(), request)
+}
+
+// 获取分组的历史消息，未开启分组历史消息时返回nil
+func (s *ServiceApi) GetGroupHistory(group string, replay ...ReplayOption) []HistoryMessage {
+	return s.hub.history.query(group, newReplay(replay))
 }
 func (s *ServiceApi) LeaveGroup(clientId, group string) {
 	s.call("LeaveGroup", context.Background(), &pb.ServiceRequest{ClientId: clientId, Group: group})
 
 }
+
+// 将某个uid的所有client加入分组
+func (s *ServiceApi) JoinGroupByUid(uid, group string) {
+	s.call("JoinGroupByUid", context.Background(), &pb.ServiceRequest{Uid: uid, Group: group})
+}
+
+// 退出所有分组
+func (s *ServiceApi) LeaveAllGroups(clientId string) {
+	s.call("LeaveAllGroups", context.Background(), &pb.ServiceRequest{ClientId: clientId})
+}
+
+// 解散分组，分组中的所有client都会退出该分组
+func (s *ServiceApi) DisbandGroup(group string) {
+	s.call("DisbandGroup", context.Background(), &pb.ServiceRequest{Group: group})
+}
+
+// 获取某个client加入的所有分组
+func (s *ServiceApi) GetGroupsByClientId(clientId string) []string {
+	var groups []string
+	responses, _ := s.call("GetGroupsByClientId", context.Background(), &pb.ServiceRequest{ClientId: clientId})
+	for _, response := range responses {
+		groups = append(groups, response.Groups...)
+	}
+	return groups
+}
+
+// 获取某个uid的所有client加入的分组
+func (s *ServiceApi) GetGroupsByUid(uid string) []string {
+	groupMaps := make(map[string]bool)
+	responses, _ := s.call("GetGroupsByUid", context.Background(), &pb.ServiceRequest{Uid: uid})
+	for _, response := range responses {
+		for _, group := range response.Groups {
+			groupMaps[group] = true
+		}
+	}
+	groups := make([]string, 0, len(groupMaps))
+	for group := range groupMaps {
+		groups = append(groups, group)
+	}
+	return groups
+}
+
 func (s *ServiceApi) GetClientCountByGroup(group string) int {
 	count := 0
 	responses, _ := s.call("GetClientCountByGroup", context.Background(), &pb.ServiceRequest{Group: group})
@@ -217,8 +362,19 @@ func (s *ServiceApi) GetAllGroups() []string {
 	}
 	return groups
 }
-func (s *ServiceApi) CloseClient(clientId string) {
-	s.call("CloseClient", context.Background(), &pb.ServiceRequest{ClientId: clientId})
+// 关闭client，message不为空时关闭前发送给该client
+func (s *ServiceApi) CloseClient(clientId string, message ...[]byte) {
+	s.CloseClientWithReason(clientId, websocket.CloseNormalClosure, "", message...)
+}
+
+// 以code和reason关闭client，1004、1005、1006、1015以及1000-4999以外的code改为1000，reason超过123字节时会被截断。
+// message不为空时关闭前发送给该client。
+func (s *ServiceApi) CloseClientWithReason(clientId string, code int, reason string, message ...[]byte) {
+	request := &pb.ServiceRequest{ClientId: clientId, CloseCode: int32(code), CloseReason: reason}
+	if len(message) > 0 {
+		request.Message = message[0]
+	}
+	s.call("CloseClient", context.Background(), request)
 }
 func (s *ServiceApi) IsOnline(clientId string) bool {
 	responses, _ := s.call("IsOnline", context.Background(), &pb.ServiceRequest{ClientId: clientId})
@@ -249,12 +405,151 @@ func (s *ServiceApi) GetInfo(clientId string) map[string]string {
 	return make(map[string]string)
 }
 
-// 全局替换
-func (s *ServiceApi) SetInfo(clientId string, info map[string]string) {
-	s.call("SetInfo", context.Background(), &pb.ServiceRequest{ClientId: clientId, Info: info})
+// 获取某个client的完整会话信息，不在线时返回nil
+func (s *ServiceApi) GetClientSession(clientId string) *pb.Client {
+	addr, err := nodeOfClientId(clientId)
+	if err != nil {
+		return nil
+	}
+	response, err := s.callNode(addr, "GetClientSession", context.Background(), &pb.ServiceRequest{ClientId: clientId})
+	if err != nil {
+		s.hub.log(LogWarn, "call method error", F("addr", addr), F("method", "GetClientSession"), F("error", err))
+		return nil
+	}
+	for _, client := range response.Clients {
+		return client
+	}
+	return nil
+}
+
+// 获取某个分组所有client的会话信息
+func (s *ServiceApi) GetSessionsByGroup(group string) []*pb.Client {
+	return s.getSessions("GetSessionsByGroup", &pb.ServiceRequest{Group: group})
+}
+
+// 获取某个uid所有client的会话信息
+func (s *ServiceApi) GetSessionsByUid(uid string) []*pb.Client {
+	return s.getSessions("GetSessionsByUid", &pb.ServiceRequest{Uid: uid})
+}
+
+// 获取所有client的会话信息
+func (s *ServiceApi) GetAllSessions() []*pb.Client {
+	return s.getSessions("GetAllSessions", &pb.ServiceRequest{})
+}
+
+func (s *ServiceApi) getSessions(method string, request *pb.ServiceRequest) []*pb.Client {
+	var clients []*pb.Client
+	responses, _ := s.call(method, context.Background(), request)
+	for _, response := range responses {
+		clients = append(clients, response.Clients...)
+	}
+	return clients
+}
+
+// 查询满足条件的所有clientId
+func (s *ServiceApi) FindClientIds(query *Query) []string {
+	var clientIds []string
+	responses, _ := s.call("QueryClients", context.Background(), &pb.ServiceRequest{Predicates: query.predicates})
+	for _, response := range responses {
+		clientIds = append(clientIds, response.ClientIds...)
+	}
+	return clientIds
+}
+
+// 查询满足条件的client数目
+func (s *ServiceApi) CountClients(query *Query) int {
+	count := 0
+	responses, _ := s.call("QueryClients", context.Background(), &pb.ServiceRequest{Predicates: query.predicates, CountOnly: true})
+	for _, response := range responses {
+		count += int(response.Count)
+	}
+	return count
+}
+
+// 获取某个client的info信息及其版本号，用于CompareAndSetInfo
+func (s *ServiceApi) GetInfoWithVersion(clientId string) (map[string]string, uint64) {
+	client := s.GetClientSession(clientId)
+	if client == nil {
+		return make(map[string]string), 0
+	}
+	return client.Info, client.InfoVersion
+}
+
+// 获取info中某个key的值并解码到value，value需为指针
+func (s *ServiceApi) GetInfoValue(clientId, key string, value interface{}) error {
+	raw, ok := s.GetInfo(clientId)[key]
+	if !ok {
+		return errors.New("info key " + key + " not exist")
+	}
+	return DecodeInfoValue(raw, value)
+}
+
+// 全局替换，返回修改后的版本号
+func (s *ServiceApi) SetInfo(clientId string, info map[string]string) (uint64, error) {
+	return s.modifyInfo("SetInfo", &pb.ServiceRequest{ClientId: clientId, Info: info})
+}
+
+// 局部更新，返回修改后的版本号
+func (s *ServiceApi) UpdateInfo(clientId string, info map[string]string) (uint64, error) {
+	return s.modifyInfo("UpdateInfo", &pb.ServiceRequest{ClientId: clientId, Info: info})
+}
+
+// 订阅uid的上下线事件，不传uid时订阅所有uid
+func (s *ServiceApi) SubscribePresence(clientId string, uids ...string) {
+	if len(uids) == 0 {
+		s.JoinGroup(clientId, PresenceChannel(""))
+		return
+	}
+	for _, uid := range uids {
+		s.JoinGroup(clientId, PresenceChannel(uid))
+	}
+}
+
+// 取消订阅uid的上下线事件，不传uid时取消订阅所有uid
+func (s *ServiceApi) UnsubscribePresence(clientId string, uids ...string) {
+	if len(uids) == 0 {
+		s.LeaveGroup(clientId, PresenceChannel(""))
+		return
+	}
+	for _, uid := range uids {
+		s.LeaveGroup(clientId, PresenceChannel(uid))
+	}
+}
+
+// 将value编码后更新到info的key中，字符串保持原样，其他类型使用json编码
+func (s *ServiceApi) SetInfoValue(clientId, key string, value interface{}) (uint64, error) {
+	raw, err := EncodeInfoValue(value)
+	if err != nil {
+		return 0, err
+	}
+	return s.UpdateInfo(clientId, map[string]string{key: raw})
+}
+
+// info的版本号等于version时才全局替换，否则返回ErrInfoConflict及当前的版本号
+func (s *ServiceApi) CompareAndSetInfo(clientId string, version uint64, info map[string]string) (uint64, error) {
+	return s.modifyInfo("CompareAndSetInfo", &pb.ServiceRequest{ClientId: clientId, Info: info, InfoVersion: version})
+}
+
+// 删除info中的部分key，返回修改后的版本号
+func (s *ServiceApi) DeleteInfoKeys(clientId string, keys ...string) (uint64, error) {
+	return s.modifyInfo("DeleteInfoKeys", &pb.ServiceRequest{ClientId: clientId, InfoKeys: keys})
 }
 
-// 局部更新
-func (s *ServiceApi) UpdateInfo(clientId string, info map[string]string) {
-	s.call("UpdateInfo", context.Background(), &pb.ServiceRequest{ClientId: clientId, Info: info})
+// 在client所在的服务上修改info
+func (s *ServiceApi) modifyInfo(method string, request *pb.ServiceRequest) (uint64, error) {
+	addr, err := nodeOfClientId(request.ClientId)
+	if err != nil {
+		return 0, err
+	}
+	response, err := s.callNode(addr, method, context.Background(), request)
+	if err != nil {
+		return 0, err
+	}
+	if response.Conflict {
+		return response.InfoVersion, ErrInfoConflict
+	}
+	if !response.Success {
+		return 0, ErrClientNotFound
+	}
+	return response.InfoVersion, nil
 }
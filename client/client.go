@@ -0,0 +1,375 @@
+// client 连接websocket服务的Go客户端，用于机器人、压测和后端服务。
+// 自动回复心跳、断线后随机退避重连，服务端开启会话恢复时重连后恢复原来的会话，
+// 自动确认可靠发送的消息并去重，支持Request/RequestClient的请求和回复。
+//
+//	c, err := client.Dial("ws://localhost:8080/ws", client.WithHeader(header))
+//	for message := range c.Messages() {...}
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/bin-x/websocket"
+	gorilla "github.com/gorilla/websocket"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	writeWait = 10 * time.Second
+
+	// 与服务端的心跳时间相同
+	defaultPongWait   = 30 * time.Second
+	defaultPingPeriod = (defaultPongWait * 9) / 10
+
+	defaultMinBackoff = 500 * time.Millisecond
+	defaultMaxBackoff = 30 * time.Second
+	// 服务不可用时避免不停地重连
+	minBackoffLimit = 100 * time.Millisecond
+)
+
+var (
+	// 正在重连或已关闭
+	ErrNotConnected = errors.New("not connected")
+	ErrClosed       = errors.New("client closed")
+)
+
+// 服务端发送的会话信息
+type Session = websocket.SessionData
+
+type Client struct {
+	url    string
+	header http.Header
+	dialer *gorilla.Dialer
+
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	reconnect  func(event websocket.CloseEvent) bool
+	pingPeriod time.Duration
+	pongWait   time.Duration
+
+	onMessage    func(message []byte)
+	onRequest    func(data json.RawMessage) (interface{}, error)
+	onConnect    func()
+	onSession    func(session Session)
+	onDisconnect func(event websocket.CloseEvent)
+	logger       websocket.Logger
+
+	mu   sync.Mutex
+	conn *gorilla.Conn
+	// 同一时间只能有一个goroutine写入
+	writeMu sync.Mutex
+	session Session
+	// 已收到的可靠消息的最大序号，只在run中使用
+	lastSeq uint64
+	// 当前连接使用了resume_token，只在run中使用
+	resuming bool
+
+	messages  chan []byte
+	requests  *pendingRequests
+	requestId uint64
+
+	closed    chan struct{}
+	closeOnce sync.Once
+	// run退出时close
+	done chan struct{}
+}
+
+// 连接服务，第一次连接失败时返回错误，之后断开会自动重连，直到调用Close
+func Dial(url string, options ...Option) (*Client, error) {
+	c := &Client{
+		url:        url,
+		dialer:     gorilla.DefaultDialer,
+		minBackoff: defaultMinBackoff,
+		maxBackoff: defaultMaxBackoff,
+		reconnect:  defaultReconnect,
+		pingPeriod: defaultPingPeriod,
+		pongWait:   defaultPongWait,
+		logger:     websocket.NewStdLogger(os.Stderr, websocket.LogError),
+		messages:   make(chan []byte, 256),
+		requests:   newPendingRequests(),
+		closed:     make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	for _, option := range options {
+		option(c)
+	}
+	conn, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	go c.run(conn)
+	return c, nil
+}
+
+// 服务端主动以1000（CloseClient）或1008（限流、策略）关闭时不重连
+func defaultReconnect(event websocket.CloseEvent) bool {
+	if event.Initiator != websocket.CloseByServer {
+		return true
+	}
+	return event.Code != gorilla.CloseNormalClosure && event.Code != gorilla.ClosePolicyViolation
+}
+
+// 收到的消息，Client关闭后close。未设置WithMessageHandler时需要及时读取，否则会阻塞读取连接。
+func (c *Client) Messages() <-chan []byte {
+	return c.messages
+}
+
+// 不再重连后close
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// 服务端开启会话恢复时为当前的clientId，否则为空
+func (c *Client) ClientId() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.session.ClientId
+}
+
+// 发送消息，正在重连时返回ErrNotConnected
+func (c *Client) Send(message []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	conn := c.currentConn()
+	if conn == nil {
+		return ErrNotConnected
+	}
+	conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return conn.WriteMessage(gorilla.TextMessage, message)
+}
+
+// 发送 {"type":msgType,"data":data}
+func (c *Client) SendEnvelope(msgType string, data interface{}) error {
+	message, err := websocket.MarshalEnvelope(msgType, "", data)
+	if err != nil {
+		return err
+	}
+	return c.Send(message)
+}
+
+// 发送 {"type":msgType,"id":"xxx","data":data} 并等待相同id的回复，如Router中handler返回的result。
+// 回复为错误消息时返回*websocket.EnvelopeError。handler没有返回result时不会回复，需要ctx设置超时。
+func (c *Client) Request(ctx context.Context, msgType string, data interface{}) (json.RawMessage, error) {
+	id := strconv.FormatUint(atomic.AddUint64(&c.requestId, 1), 10)
+	message, err := websocket.MarshalEnvelope(msgType, id, data)
+	if err != nil {
+		return nil, err
+	}
+	reply := c.requests.add(id)
+	defer c.requests.remove(id)
+	if err := c.Send(message); err != nil {
+		return nil, err
+	}
+	select {
+	case envelope := <-reply:
+		if envelope.Error != nil {
+			return nil, envelope.Error
+		}
+		return envelope.Data, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.closed:
+		return nil, ErrClosed
+	}
+}
+
+// 以1000关闭连接，不再重连，可以通过Done等待关闭完成
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	if conn := c.currentConn(); conn != nil {
+		conn.WriteControl(gorilla.CloseMessage, gorilla.FormatCloseMessage(gorilla.CloseNormalClosure, ""), time.Now().Add(writeWait))
+		conn.Close()
+	}
+	return nil
+}
+
+func (c *Client) currentConn() *gorilla.Conn {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn
+}
+
+// 连接服务，有会话时带上resume_token
+func (c *Client) dial() (*gorilla.Conn, error) {
+	u, err := url.Parse(c.url)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	token := c.session.ResumeToken
+	c.mu.Unlock()
+	c.resuming = token != ""
+	if token != "" {
+		query := u.Query()
+		query.Set("resume_token", token)
+		u.RawQuery = query.Encode()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-c.closed:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	conn, _, err := c.dialer.DialContext(ctx, u.String(), c.header)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.conn = conn
+	c.mu.Unlock()
+	// 连接成功前已调用Close
+	select {
+	case <-c.closed:
+		conn.Close()
+		return nil, ErrClosed
+	default:
+	}
+	if c.onConnect != nil {
+		c.onConnect()
+	}
+	return conn, nil
+}
+
+func (c *Client) run(conn *gorilla.Conn) {
+	defer close(c.done)
+	defer close(c.messages)
+	for {
+		// 没有恢复会话时可靠消息的序号重新开始，恢复时由会话信息决定
+		if !c.resuming {
+			c.lastSeq = 0
+		}
+		event := c.read(conn)
+		c.mu.Lock()
+		c.conn = nil
+		// 服务端发送了close frame，会话已结束
+		if event.Initiator == websocket.CloseByServer {
+			c.session.ResumeToken = ""
+		}
+		c.mu.Unlock()
+		select {
+		case <-c.closed:
+			return
+		default:
+		}
+		logTo(c.logger, websocket.LogInfo, "disconnected", websocket.F("code", event.Code), websocket.F("reason", event.Reason))
+		if c.onDisconnect != nil {
+			c.onDisconnect(event)
+		}
+		if !c.reconnect(event) {
+			c.closeOnce.Do(func() { close(c.closed) })
+			return
+		}
+		if conn = c.redial(); conn == nil {
+			return
+		}
+	}
+}
+
+// 随机退避后重连，直到成功或已关闭
+func (c *Client) redial() *gorilla.Conn {
+	for attempt := 0; ; attempt++ {
+		select {
+		case <-time.After(backoff(c.minBackoff, c.maxBackoff, attempt)):
+		case <-c.closed:
+			return nil
+		}
+		conn, err := c.dial()
+		if err == nil {
+			logTo(c.logger, websocket.LogInfo, "reconnected", websocket.F("attempt", attempt+1))
+			return conn
+		}
+		if err == ErrClosed {
+			return nil
+		}
+		logTo(c.logger, websocket.LogWarn, "reconnect error", websocket.F("attempt", attempt+1), websocket.F("error", err))
+	}
+}
+
+// 第attempt次重连前等待的时间，在 min*2^attempt（最长max）的一半到全部之间随机，避免所有客户端同时重连
+func backoff(min, max time.Duration, attempt int) time.Duration {
+	d := max
+	if attempt < 32 && min<<uint(attempt) < max {
+		d = min << uint(attempt)
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+// 读取连接直到断开，返回断开的原因
+func (c *Client) read(conn *gorilla.Conn) websocket.CloseEvent {
+	done := make(chan struct{})
+	defer close(done)
+	go c.ping(conn, done)
+
+	conn.SetReadDeadline(time.Now().Add(c.pongWait))
+	conn.SetPongHandler(func(string) error { conn.SetReadDeadline(time.Now().Add(c.pongWait)); return nil })
+	conn.SetPingHandler(func(data string) error {
+		conn.SetReadDeadline(time.Now().Add(c.pongWait))
+		err := conn.WriteControl(gorilla.PongMessage, []byte(data), time.Now().Add(writeWait))
+		if err == gorilla.ErrCloseSent {
+			return nil
+		}
+		if e, ok := err.(net.Error); ok && e.Temporary() {
+			return nil
+		}
+		return err
+	})
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			conn.Close()
+			return closeEventFromError(err)
+		}
+		conn.SetReadDeadline(time.Now().Add(c.pongWait))
+		c.handle(message)
+	}
+}
+
+func (c *Client) ping(conn *gorilla.Conn, done chan struct{}) {
+	ticker := time.NewTicker(c.pingPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(gorilla.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// 收到服务端的close frame时Initiator为CloseByServer
+func closeEventFromError(err error) websocket.CloseEvent {
+	if e, ok := err.(*gorilla.CloseError); ok {
+		if e.Code == gorilla.CloseAbnormalClosure {
+			return websocket.CloseEvent{Code: e.Code, Reason: e.Text, Initiator: websocket.CloseByPeer}
+		}
+		return websocket.CloseEvent{Code: e.Code, Reason: e.Text, Initiator: websocket.CloseByServer}
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return websocket.CloseEvent{Code: gorilla.CloseAbnormalClosure, Reason: err.Error(), Initiator: websocket.CloseByTimeout}
+	}
+	return websocket.CloseEvent{Code: gorilla.CloseAbnormalClosure, Reason: err.Error(), Initiator: websocket.CloseByPeer}
+}
+
+func logTo(logger websocket.Logger, level websocket.LogLevel, msg string, fields ...websocket.Field) {
+	if logger != nil {
+		logger.Log(level, msg, fields...)
+	}
+}
@@ -0,0 +1,215 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/bin-x/websocket"
+	gorilla "github.com/gorilla/websocket"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 模拟服务端，每个连接依次调用handlers中的一个
+func newTestServer(t *testing.T, handlers ...func(conn *gorilla.Conn, r *http.Request)) (*httptest.Server, string) {
+	connections := make(chan func(conn *gorilla.Conn, r *http.Request), len(handlers))
+	for _, handler := range handlers {
+		connections <- handler
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler := <-connections
+		conn, err := (&gorilla.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		handler(conn, r)
+	}))
+	return server, "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func writeEnvelope(conn *gorilla.Conn, msgType, id string, data interface{}) {
+	message, _ := websocket.MarshalEnvelope(msgType, id, data)
+	conn.WriteMessage(gorilla.TextMessage, message)
+}
+
+func readEnvelope(t *testing.T, conn *gorilla.Conn) *websocket.Envelope {
+	_, message, err := conn.ReadMessage()
+	if err != nil {
+		t.Error(err)
+		return &websocket.Envelope{}
+	}
+	var envelope websocket.Envelope
+	json.Unmarshal(message, &envelope)
+	return &envelope
+}
+
+func TestClient_resume(t *testing.T) {
+	t.Parallel()
+	server, url := newTestServer(t,
+		func(conn *gorilla.Conn, r *http.Request) {
+			writeEnvelope(conn, websocket.EnvelopeTypeSession, "", Session{ClientId: "c1", ResumeToken: "t1"})
+			writeEnvelope(conn, websocket.EnvelopeTypeReliable, "1", "a")
+			writeEnvelope(conn, websocket.EnvelopeTypeReliable, "2", json.RawMessage(`{"type":"b"}`))
+			for _, id := range []string{"1", "2"} {
+				if envelope := readEnvelope(t, conn); envelope.Type != websocket.EnvelopeTypeAck || envelope.Id != id {
+					t.Errorf("ack got = %+v, want %v", envelope, id)
+				}
+			}
+			// 网络断开，没有close frame
+			conn.UnderlyingConn().Close()
+		},
+		func(conn *gorilla.Conn, r *http.Request) {
+			if token := r.URL.Query().Get("resume_token"); token != "t1" {
+				t.Errorf("resume_token got = %v", token)
+			}
+			writeEnvelope(conn, websocket.EnvelopeTypeSession, "", Session{ClientId: "c1", ResumeToken: "t1", Resumed: true})
+			// 重发未确认的消息
+			writeEnvelope(conn, websocket.EnvelopeTypeReliable, "2", json.RawMessage(`{"type":"b"}`))
+			writeEnvelope(conn, websocket.EnvelopeTypeReliable, "3", "c")
+			for _, id := range []string{"2", "3"} {
+				if envelope := readEnvelope(t, conn); envelope.Id != id {
+					t.Errorf("ack got = %+v, want %v", envelope, id)
+				}
+			}
+			conn.WriteMessage(gorilla.CloseMessage, gorilla.FormatCloseMessage(gorilla.CloseNormalClosure, "bye"))
+			conn.ReadMessage()
+		},
+	)
+	defer server.Close()
+
+	var events []websocket.CloseEvent
+	c, err := Dial(url, WithBackoff(time.Millisecond, 10*time.Millisecond), WithLogger(nil),
+		WithDisconnectHandler(func(event websocket.CloseEvent) { events = append(events, event) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var messages []string
+	for message := range c.Messages() {
+		messages = append(messages, string(message))
+	}
+	<-c.Done()
+	if got := strings.Join(messages, ","); got != `a,{"type":"b"},c` {
+		t.Errorf("messages got = %v", got)
+	}
+	if len(events) != 2 || events[0].Initiator != websocket.CloseByPeer || events[1] != (websocket.CloseEvent{Code: 1000, Reason: "bye", Initiator: websocket.CloseByServer}) {
+		t.Errorf("disconnect events got = %+v", events)
+	}
+	if c.ClientId() != "c1" {
+		t.Errorf("ClientId() got = %v", c.ClientId())
+	}
+}
+
+func TestClient_Request(t *testing.T) {
+	t.Parallel()
+	answered := make(chan struct{})
+	server, url := newTestServer(t, func(conn *gorilla.Conn, r *http.Request) {
+		// 服务端的RequestClient
+		writeEnvelope(conn, websocket.EnvelopeTypeRequest, "r1", 2)
+		if envelope := readEnvelope(t, conn); envelope.Type != websocket.EnvelopeTypeResponse || envelope.Id != "r1" || string(envelope.Data) != "4" {
+			t.Errorf("response got = %+v", envelope)
+		}
+		writeEnvelope(conn, websocket.EnvelopeTypeRequest, "r2", -1)
+		if envelope := readEnvelope(t, conn); envelope.Error == nil || envelope.Error.Code != "negative" {
+			t.Errorf("error response got = %+v", envelope)
+		}
+		close(answered)
+		// 客户端的Request，回复一个成功一个错误
+		for i := 0; i < 2; i++ {
+			request := readEnvelope(t, conn)
+			if request.Type == "fail" {
+				conn.WriteJSON(websocket.Envelope{Type: "error", Id: request.Id, Error: &websocket.EnvelopeError{Code: "bad", Message: "bad"}})
+				continue
+			}
+			writeEnvelope(conn, request.Type, request.Id, "pong")
+		}
+		conn.ReadMessage()
+	})
+	defer server.Close()
+
+	c, err := Dial(url, WithoutReconnect(), WithLogger(nil),
+		WithRequestHandler(func(data json.RawMessage) (interface{}, error) {
+			var n int
+			json.Unmarshal(data, &n)
+			if n < 0 {
+				return nil, &websocket.EnvelopeError{Code: "negative", Message: "negative"}
+			}
+			return n * 2, nil
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	<-answered
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if result, err := c.Request(ctx, "ping", nil); err != nil || string(result) != `"pong"` {
+		t.Errorf("Request() got = %s, %v", result, err)
+	}
+	if _, err := c.Request(ctx, "fail", nil); err == nil || err.Error() != "bad: bad" {
+		t.Errorf("Request() error got = %v", err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{0, 50 * time.Millisecond, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond, 400 * time.Millisecond},
+		{10, 500 * time.Millisecond, time.Second},
+		{100, 500 * time.Millisecond, time.Second},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			if d := backoff(100*time.Millisecond, time.Second, tt.attempt); d < tt.min || d > tt.max {
+				t.Errorf("backoff(%v) = %v, want between %v and %v", tt.attempt, d, tt.min, tt.max)
+			}
+		}
+	}
+}
+
+func TestWithBackoff(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		min, max         time.Duration
+		wantMin, wantMax time.Duration
+	}{
+		{time.Second, time.Minute, time.Second, time.Minute},
+		{0, 0, minBackoffLimit, minBackoffLimit},
+		{time.Millisecond, 10 * time.Millisecond, minBackoffLimit, minBackoffLimit},
+		{0, time.Second, minBackoffLimit, time.Second},
+		{time.Second, time.Millisecond, time.Second, time.Second},
+	}
+	for _, tt := range tests {
+		c := &Client{}
+		WithBackoff(tt.min, tt.max)(c)
+		if c.minBackoff != tt.wantMin || c.maxBackoff != tt.wantMax {
+			t.Errorf("WithBackoff(%v, %v) got = %v, %v, want %v, %v", tt.min, tt.max, c.minBackoff, c.maxBackoff, tt.wantMin, tt.wantMax)
+		}
+	}
+}
+
+func TestDefaultReconnect(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		event websocket.CloseEvent
+		want  bool
+	}{
+		{websocket.CloseEvent{Code: 1006, Initiator: websocket.CloseByPeer}, true},
+		{websocket.CloseEvent{Code: 1006, Initiator: websocket.CloseByTimeout}, true},
+		{websocket.CloseEvent{Code: 1000, Initiator: websocket.CloseByServer}, false},
+		{websocket.CloseEvent{Code: 1008, Initiator: websocket.CloseByServer}, false},
+		{websocket.CloseEvent{Code: websocket.CloseLifetimeExceeded, Initiator: websocket.CloseByServer}, true},
+	}
+	for _, tt := range tests {
+		if got := defaultReconnect(tt.event); got != tt.want {
+			t.Errorf("defaultReconnect(%+v) = %v, want %v", tt.event, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package client
+
+import (
+	"encoding/json"
+	"github.com/bin-x/websocket"
+	"strconv"
+	"sync"
+)
+
+// 等待回复的Request
+type pendingRequests struct {
+	mu       sync.Mutex
+	requests map[string]chan *websocket.Envelope
+}
+
+func newPendingRequests() *pendingRequests {
+	return &pendingRequests{requests: make(map[string]chan *websocket.Envelope)}
+}
+
+func (p *pendingRequests) add(id string) chan *websocket.Envelope {
+	reply := make(chan *websocket.Envelope, 1)
+	p.mu.Lock()
+	p.requests[id] = reply
+	p.mu.Unlock()
+	return reply
+}
+
+func (p *pendingRequests) remove(id string) {
+	p.mu.Lock()
+	delete(p.requests, id)
+	p.mu.Unlock()
+}
+
+// 不是等待中的请求时返回false
+func (p *pendingRequests) resolve(id string, envelope *websocket.Envelope) bool {
+	p.mu.Lock()
+	reply, ok := p.requests[id]
+	delete(p.requests, id)
+	p.mu.Unlock()
+	if ok {
+		reply <- envelope
+	}
+	return ok
+}
+
+// 处理内置的消息，其他消息交给应用，只在run中调用
+func (c *Client) handle(message []byte) {
+	if len(message) == 0 || message[0] != '{' {
+		c.deliver(message)
+		return
+	}
+	var envelope websocket.Envelope
+	if err := json.Unmarshal(message, &envelope); err != nil {
+		c.deliver(message)
+		return
+	}
+	switch envelope.Type {
+	case websocket.EnvelopeTypeSession:
+		c.handleSession(envelope.Data)
+		return
+	case websocket.EnvelopeTypeReliable:
+		c.handleReliable(&envelope)
+		return
+	case websocket.EnvelopeTypeRequest:
+		if c.onRequest != nil {
+			go c.answer(&envelope)
+			return
+		}
+	default:
+		if envelope.Id != "" && c.requests.resolve(envelope.Id, &envelope) {
+			return
+		}
+	}
+	c.deliver(message)
+}
+
+func (c *Client) deliver(message []byte) {
+	if c.onMessage != nil {
+		c.onMessage(message)
+		return
+	}
+	select {
+	case c.messages <- message:
+	case <-c.closed:
+	}
+}
+
+func (c *Client) handleSession(data json.RawMessage) {
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		logTo(c.logger, websocket.LogWarn, "invalid session", websocket.F("error", err))
+		return
+	}
+	// 新的会话，可靠消息的序号重新开始
+	if !session.Resumed {
+		c.lastSeq = 0
+	}
+	c.mu.Lock()
+	c.session = session
+	c.mu.Unlock()
+	if c.onSession != nil {
+		c.onSession(session)
+	}
+}
+
+// 确认可靠消息，重复的消息只确认不交给应用
+func (c *Client) handleReliable(envelope *websocket.Envelope) {
+	seq, err := strconv.ParseUint(envelope.Id, 10, 64)
+	if err != nil {
+		return
+	}
+	ack, _ := websocket.MarshalEnvelope(websocket.EnvelopeTypeAck, envelope.Id, nil)
+	if seq <= c.lastSeq {
+		c.Send(ack)
+		return
+	}
+	c.lastSeq = seq
+	message := []byte(envelope.Data)
+	// 服务端将不是json的消息编码为json字符串
+	var s string
+	if len(message) > 0 && message[0] == '"' && json.Unmarshal(message, &s) == nil {
+		message = []byte(s)
+	}
+	c.deliver(message)
+	c.Send(ack)
+}
+
+// 回复服务端的RequestClient
+func (c *Client) answer(request *websocket.Envelope) {
+	response := websocket.Envelope{Type: websocket.EnvelopeTypeResponse, Id: request.Id}
+	result, err := c.onRequest(request.Data)
+	if err == nil {
+		response.Data, err = json.Marshal(result)
+	}
+	if err != nil {
+		response.Data = nil
+		response.Error = toEnvelopeError(err)
+	}
+	message, _ := json.Marshal(response)
+	if err := c.Send(message); err != nil {
+		logTo(c.logger, websocket.LogWarn, "send response error", websocket.F("id", request.Id), websocket.F("error", err))
+	}
+}
+
+func toEnvelopeError(err error) *websocket.EnvelopeError {
+	if e, ok := err.(*websocket.EnvelopeError); ok {
+		return e
+	}
+	return &websocket.EnvelopeError{Code: websocket.ErrorCodeInternal, Message: err.Error()}
+}
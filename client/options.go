@@ -0,0 +1,103 @@
+package client
+
+import (
+	"encoding/json"
+	"github.com/bin-x/websocket"
+	gorilla "github.com/gorilla/websocket"
+	"net/http"
+	"time"
+)
+
+// 创建Client时的可选配置
+type Option func(c *Client)
+
+// 连接时的请求头，如认证用的Cookie、Authorization
+func WithHeader(header http.Header) Option {
+	return func(c *Client) {
+		c.header = header
+	}
+}
+
+func WithDialer(dialer *gorilla.Dialer) Option {
+	return func(c *Client) {
+		c.dialer = dialer
+	}
+}
+
+// 重连的等待时间，从min开始每次翻倍，最长max，实际等待时间在一半到全部之间随机。
+// min最少为100ms，max小于min时使用min。
+func WithBackoff(min, max time.Duration) Option {
+	return func(c *Client) {
+		if min < minBackoffLimit {
+			min = minBackoffLimit
+		}
+		if max < min {
+			max = min
+		}
+		c.minBackoff = min
+		c.maxBackoff = max
+	}
+}
+
+// 根据断开的原因决定是否重连，默认服务端以1000或1008关闭时不重连
+func WithReconnectPolicy(policy func(event websocket.CloseEvent) bool) Option {
+	return func(c *Client) {
+		c.reconnect = policy
+	}
+}
+
+// 断开后不重连
+func WithoutReconnect() Option {
+	return WithReconnectPolicy(func(event websocket.CloseEvent) bool { return false })
+}
+
+// 每pingPeriod发送一次ping，超过pongWait没有收到任何消息时断开重连，pingPeriod需小于pongWait
+func WithKeepalive(pingPeriod, pongWait time.Duration) Option {
+	return func(c *Client) {
+		c.pingPeriod = pingPeriod
+		c.pongWait = pongWait
+	}
+}
+
+// 收到消息时调用，设置后Messages不再收到消息。在读取连接的goroutine中调用，不应长时间阻塞。
+func WithMessageHandler(handler func(message []byte)) Option {
+	return func(c *Client) {
+		c.onMessage = handler
+	}
+}
+
+// 处理服务端RequestClient发来的请求，返回值作为回复的data，返回的error作为错误回复，
+// *websocket.EnvelopeError使用其中的code。未设置时请求作为普通消息收到。
+func WithRequestHandler(handler func(data json.RawMessage) (interface{}, error)) Option {
+	return func(c *Client) {
+		c.onRequest = handler
+	}
+}
+
+// 每次连接（包括重连）成功后调用
+func WithConnectHandler(handler func()) Option {
+	return func(c *Client) {
+		c.onConnect = handler
+	}
+}
+
+// 服务端开启会话恢复时，收到会话信息后调用，Resumed为false表示这是一个新的会话
+func WithSessionHandler(handler func(session Session)) Option {
+	return func(c *Client) {
+		c.onSession = handler
+	}
+}
+
+// 连接断开时调用
+func WithDisconnectHandler(handler func(event websocket.CloseEvent)) Option {
+	return func(c *Client) {
+		c.onDisconnect = handler
+	}
+}
+
+// 设置日志，默认只输出错误日志到标准错误
+func WithLogger(logger websocket.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
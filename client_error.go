@@ -0,0 +1,132 @@
+package websocket
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"net"
+	"os"
+	"runtime/debug"
+)
+
+// 传给OnError的错误类型
+type ErrorKind string
+
+const (
+	// Application的方法或消息中间件panic
+	ErrorKindPanic ErrorKind = "panic"
+	// 客户端违反websocket协议
+	ErrorKindProtocol ErrorKind = "protocol"
+	// 客户端发送的消息超过maxMessageSize
+	ErrorKindMessageTooLarge ErrorKind = "message_too_large"
+	// 读取超时等网络错误
+	ErrorKindRead  ErrorKind = "read"
+	ErrorKindWrite ErrorKind = "write"
+)
+
+// 某个client发生的错误
+type ClientError struct {
+	Kind ErrorKind
+	Err  error
+	// panic时的调用栈
+	Stack []byte
+}
+
+func (e *ClientError) Error() string {
+	return string(e.Kind) + ": " + e.Err.Error()
+}
+
+func (e *ClientError) Unwrap() error {
+	return e.Err
+}
+
+// Application实现该接口时，client发生错误会调用OnError，err为*ClientError。
+// 客户端正常关闭连接、服务端主动关闭连接不会调用。
+type ErrorHandler interface {
+	OnError(clientId string, err error)
+}
+
+// Application的方法panic时的处理方式
+type PanicPolicy int
+
+const (
+	// 以1011关闭连接，默认
+	PanicCloseConnection PanicPolicy = iota
+	// 忽略该消息，继续处理之后的消息
+	PanicKeepConnection
+	// 记录日志后退出进程
+	PanicCrash
+)
+
+// 设置OnConnect、OnMessage、OnClose和消息中间件panic时的处理方式，都会调用OnError
+func WithPanicPolicy(policy PanicPolicy) ServiceOption {
+	return func(sh *ServiceHub) {
+		sh.panicPolicy = policy
+	}
+}
+
+// 调用Application的方法，panic时按panicPolicy处理，需要关闭连接时返回false
+func (c *Client) protect(fn func()) (ok bool) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		err, isErr := r.(error)
+		if !isErr {
+			err = fmt.Errorf("%v", r)
+		}
+		stack := debug.Stack()
+		c.hub.log(LogError, "panic in application", F("client_id", c.id), F("error", err), F("stack", string(stack)))
+		c.onError(&ClientError{Kind: ErrorKindPanic, Err: err, Stack: stack})
+		if c.hub.panicPolicy == PanicCrash {
+			os.Exit(2)
+		}
+		ok = c.hub.panicPolicy == PanicKeepConnection
+	}()
+	fn()
+	return true
+}
+
+// 读取错误对应的类型，客户端关闭连接时返回空
+func readErrorKind(err error) ErrorKind {
+	if err == websocket.ErrReadLimit {
+		return ErrorKindMessageTooLarge
+	}
+	var closeErr *websocket.CloseError
+	if errors.As(err, &closeErr) {
+		return ""
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return ErrorKindRead
+	}
+	return ErrorKindProtocol
+}
+
+// 连接出错，done已关闭时为服务端关闭了连接，不再通知
+func (c *Client) connError(kind ErrorKind, err error, done chan struct{}) {
+	if kind == "" {
+		return
+	}
+	select {
+	case <-done:
+		return
+	default:
+	}
+	c.hub.log(LogDebug, "client connection error", F("client_id", c.id), F("kind", kind), F("error", err))
+	c.onError(&ClientError{Kind: kind, Err: err})
+}
+
+func (c *Client) onError(err *ClientError) {
+	handler, ok := c.hub.application.(ErrorHandler)
+	if !ok {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			c.hub.log(LogError, "panic in OnError", F("client_id", c.id), F("error", r))
+		}
+	}()
+	handler.OnError(c.id, err)
+}
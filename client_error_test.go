@@ -0,0 +1,86 @@
+package websocket
+
+import (
+	"errors"
+	"github.com/gorilla/websocket"
+	"net"
+	"testing"
+)
+
+type errorApp struct {
+	testApp
+	errors []*ClientError
+}
+
+func (a *errorApp) OnMessage(clientId string, message []byte) {
+	panic("boom")
+}
+
+func (a *errorApp) OnError(clientId string, err error) {
+	var clientErr *ClientError
+	if errors.As(err, &clientErr) {
+		a.errors = append(a.errors, clientErr)
+	}
+}
+
+func TestClient_protect(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		policy PanicPolicy
+		want   bool
+	}{
+		{"close", PanicCloseConnection, false},
+		{"keep", PanicKeepConnection, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := CreateHub()
+			app := &errorApp{}
+			hub.application = app
+			hub.panicPolicy = tt.policy
+			client := hub.clients["1"]
+			if got := client.protect(func() { client.handleMessage([]byte("hi")) }); got != tt.want {
+				t.Errorf("protect() = %v, want %v", got, tt.want)
+			}
+			if len(app.errors) != 1 || app.errors[0].Kind != ErrorKindPanic || app.errors[0].Err.Error() != "boom" || len(app.errors[0].Stack) == 0 {
+				t.Errorf("OnError got = %+v", app.errors)
+			}
+		})
+	}
+}
+
+func TestReadErrorKind(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		err  error
+		want ErrorKind
+	}{
+		{websocket.ErrReadLimit, ErrorKindMessageTooLarge},
+		{&websocket.CloseError{Code: websocket.CloseNormalClosure}, ""},
+		{&net.OpError{Op: "read", Err: errors.New("i/o timeout")}, ErrorKindRead},
+		{errors.New("websocket: bad opcode"), ErrorKindProtocol},
+	}
+	for _, tt := range tests {
+		if got := readErrorKind(tt.err); got != tt.want {
+			t.Errorf("readErrorKind(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestClient_connError(t *testing.T) {
+	t.Parallel()
+	hub := CreateHub()
+	app := &errorApp{}
+	hub.application = app
+	client := hub.clients["1"]
+	done := make(chan struct{})
+	client.connError(ErrorKindWrite, errors.New("broken pipe"), done)
+	client.connError("", errors.New("closed"), done)
+	close(done)
+	// 服务端已关闭连接
+	client.connError(ErrorKindWrite, errors.New("use of closed network connection"), done)
+	if len(app.errors) != 1 || app.errors[0].Kind != ErrorKindWrite {
+		t.Errorf("OnError got = %+v", app.errors)
+	}
+}
@@ -0,0 +1,166 @@
+package websocket
+
+import (
+	"errors"
+	"github.com/gorilla/websocket"
+	"net"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+// 服务端主动关闭连接时使用的close code，4000-4999为应用自定义范围
+const (
+	CloseIdleTimeout      = 4000
+	CloseLifetimeExceeded = 4001
+	CloseSlowConsumer     = 4002
+)
+
+// 谁关闭了连接
+type CloseInitiator string
+
+const (
+	// 客户端关闭或网络断开
+	CloseByPeer CloseInitiator = "peer"
+	// CloseClient、限流、panic等服务端主动关闭
+	CloseByServer CloseInitiator = "server"
+	// 空闲超时、超过最长时间或心跳超时
+	CloseByTimeout CloseInitiator = "timeout"
+	// 发送队列已满
+	CloseBySlowConsumer CloseInitiator = "slow_consumer"
+)
+
+// close frame的原因最长123字节
+const maxCloseReasonLength = 123
+
+// Application的方法panic时使用
+var closeInternalError = CloseEvent{Code: websocket.CloseInternalServerErr, Reason: "internal error", Initiator: CloseByServer}
+
+// 连接关闭的原因
+type CloseEvent struct {
+	Code      int            `json:"code"`
+	Reason    string         `json:"reason"`
+	Initiator CloseInitiator `json:"initiator"`
+}
+
+// Application实现该接口时，会话结束调用OnCloseEvent代替OnClose
+type CloseEventHandler interface {
+	OnCloseEvent(clientId string, event CloseEvent)
+}
+
+// 一段时间内没有收到客户端的消息时关闭连接，resetOnSend为true时发送消息给客户端也会重新计时。
+// 以CloseIdleTimeout关闭。
+func WithIdleTimeout(timeout time.Duration, resetOnSend bool) ServiceOption {
+	return func(sh *ServiceHub) {
+		sh.idleTimeout = timeout
+		sh.idleResetOnSend = resetOnSend
+	}
+}
+
+// 会话的最长时间，超过后以CloseLifetimeExceeded关闭，客户端需要重新连接并认证。
+// 恢复会话不会重新计时。
+func WithMaxLifetime(lifetime time.Duration) ServiceOption {
+	return func(sh *ServiceHub) {
+		sh.maxLifetime = lifetime
+	}
+}
+
+// 发送队列已满时关闭连接，而不是丢弃消息，以CloseSlowConsumer关闭
+func WithCloseSlowConsumers() ServiceOption {
+	return func(sh *ServiceHub) {
+		sh.closeSlowConsumers = true
+	}
+}
+
+// 从读写错误中获取关闭原因
+func closeEventFromError(err error) CloseEvent {
+	if e, ok := err.(*websocket.CloseError); ok {
+		return CloseEvent{Code: e.Code, Reason: e.Text, Initiator: CloseByPeer}
+	}
+	if err == websocket.ErrReadLimit {
+		return CloseEvent{Code: websocket.CloseMessageTooBig, Reason: "message too big", Initiator: CloseByServer}
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return CloseEvent{Code: websocket.CloseAbnormalClosure, Reason: err.Error(), Initiator: CloseByTimeout}
+	}
+	return CloseEvent{Code: websocket.CloseAbnormalClosure, Reason: err.Error(), Initiator: CloseByPeer}
+}
+
+// 截断过长的原因，不截断多字节字符
+func closeReason(reason string) string {
+	if len(reason) <= maxCloseReasonLength {
+		return reason
+	}
+	n := maxCloseReasonLength
+	for n > 0 && !utf8.RuneStart(reason[n]) {
+		n--
+	}
+	return reason[:n]
+}
+
+// 1004、1005、1006、1015以及1000-4999以外的code不能在close frame中发送，改为1000
+func closeCode(code int) int {
+	switch {
+	case code < 1000 || code > 4999:
+		return websocket.CloseNormalClosure
+	case code == 1004, code == websocket.CloseNoStatusReceived, code == websocket.CloseAbnormalClosure, code == websocket.CloseTLSHandshake:
+		return websocket.CloseNormalClosure
+	}
+	return code
+}
+
+func (c *Client) touch() {
+	atomic.StoreInt64(&c.lastActive, time.Now().UnixNano())
+}
+
+// 距离空闲超时还剩的时间，小于等于0时已超时
+func (c *Client) idleRemaining(now time.Time) time.Duration {
+	last := time.Unix(0, atomic.LoadInt64(&c.lastActive))
+	return c.hub.idleTimeout - now.Sub(last)
+}
+
+func (c *Client) onClose() {
+	if atomic.LoadInt32(&c.aborted) == 1 {
+		return
+	}
+	c.protect(func() {
+		if handler, ok := c.hub.application.(CloseEventHandler); ok {
+			handler.OnCloseEvent(c.id, c.closeEvent)
+			return
+		}
+		c.hub.application.OnClose(c.id)
+	})
+}
+
+// 还未调用OnConnect时关闭，Application不会收到OnClose
+func (c *Client) abort(event CloseEvent) {
+	atomic.StoreInt32(&c.aborted, 1)
+	c.requestClose(event)
+}
+
+// 发送队列已满时请求关闭，push可能在hub的run中调用，不能等待
+func (c *Client) closeSlow() {
+	if atomic.CompareAndSwapInt32(&c.slowClosing, 0, 1) {
+		c.hub.log(LogWarn, "send queue full, close slow consumer", F("client_id", c.id))
+		go c.requestClose(CloseEvent{Code: CloseSlowConsumer, Reason: "slow consumer", Initiator: CloseBySlowConsumer})
+	}
+}
+
+// 停止write后发送队列中剩余的消息，如CloseClient的最后一条消息，最多等待writeWait，只在close中调用
+func (c *Client) flush() {
+	close(c.connDone)
+	<-c.writerDone
+	c.connDone = nil
+	c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	for {
+		select {
+		case message := <-c.send:
+			if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package websocket
+
+import (
+	"errors"
+	pb "github.com/bin-x/websocket/proto"
+	"github.com/gorilla/websocket"
+	"strings"
+	"testing"
+	"time"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestCloseEventFromError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		err  error
+		want CloseEvent
+	}{
+		{"close frame", &websocket.CloseError{Code: websocket.CloseGoingAway, Text: "bye"}, CloseEvent{Code: websocket.CloseGoingAway, Reason: "bye", Initiator: CloseByPeer}},
+		{"network error", errors.New("reset"), CloseEvent{Code: websocket.CloseAbnormalClosure, Reason: "reset", Initiator: CloseByPeer}},
+		{"read limit", websocket.ErrReadLimit, CloseEvent{Code: websocket.CloseMessageTooBig, Reason: "message too big", Initiator: CloseByServer}},
+		{"timeout", timeoutError{}, CloseEvent{Code: websocket.CloseAbnormalClosure, Reason: "i/o timeout", Initiator: CloseByTimeout}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := closeEventFromError(tt.err); got != tt.want {
+				t.Errorf("closeEventFromError() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_idleRemaining(t *testing.T) {
+	t.Parallel()
+	c := &Client{hub: &ServiceHub{idleTimeout: time.Minute}}
+	c.touch()
+	if remaining := c.idleRemaining(time.Now()); remaining <= 0 || remaining > time.Minute {
+		t.Errorf("idleRemaining() got = %v", remaining)
+	}
+	if remaining := c.idleRemaining(time.Now().Add(2 * time.Minute)); remaining > 0 {
+		t.Errorf("idleRemaining() after timeout got = %v", remaining)
+	}
+}
+
+func TestCloseReason(t *testing.T) {
+	t.Parallel()
+	if got := closeReason("bye"); got != "bye" {
+		t.Errorf("closeReason() got = %v", got)
+	}
+	// 3字节的字符不能被截断
+	long := strings.Repeat("关", 50)
+	if got := closeReason(long); len(got) != 123 || got != long[:123] {
+		t.Errorf("closeReason() got len = %v", len(got))
+	}
+	if got := closeReason("a" + long); len(got) != 121 {
+		t.Errorf("closeReason() got len = %v", len(got))
+	}
+}
+
+func TestRpcMethods_CloseClient(t *testing.T) {
+	t.Parallel()
+	client := &Client{id: "1", send: make(chan []byte, 1), done: make(chan CloseEvent)}
+	rm := &rpcMethods{hub: &ServiceHub{clients: map[string]*Client{"1": client}}}
+	events := make(chan CloseEvent, 1)
+	go func() { events <- <-client.done }()
+	rm.CloseClient(nil, &pb.ServiceRequest{ClientId: "1", CloseCode: 4100, CloseReason: "kicked", Message: []byte("bye")})
+	if event := <-events; event != (CloseEvent{Code: 4100, Reason: "kicked", Initiator: CloseByServer}) {
+		t.Errorf("close event got = %+v", event)
+	}
+	if message := <-client.send; string(message) != "bye" {
+		t.Errorf("final message got = %s", message)
+	}
+
+	go func() { events <- <-client.done }()
+	rm.CloseClient(nil, &pb.ServiceRequest{ClientId: "1", CloseCode: websocket.CloseAbnormalClosure})
+	if event := <-events; event.Code != websocket.CloseNormalClosure || event.Initiator != CloseByServer {
+		t.Errorf("close event got = %+v", event)
+	}
+}
+
+func TestClient_closeSlow(t *testing.T) {
+	t.Parallel()
+	hub := CreateHub()
+	hub.closeSlowConsumers = true
+	client := &Client{hub: hub, id: "3", send: make(chan []byte), done: make(chan CloseEvent)}
+	if client.push([]byte("a")) || client.push([]byte("b")) {
+		t.Errorf("push() to a full queue should fail")
+	}
+	if event := <-client.done; event.Code != CloseSlowConsumer || event.Initiator != CloseBySlowConsumer {
+		t.Errorf("close event got = %+v", event)
+	}
+	select {
+	case <-client.done:
+		t.Errorf("slow consumer closed twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestClient_abort(t *testing.T) {
+	t.Parallel()
+	hub := CreateHub()
+	app := &resumeApp{closed: make(chan string, 1)}
+	hub.application = app
+	hub.lanIp = "127.0.0.1"
+	hub.rpcPort = 9101
+	server, peer := newTestConn(t)
+	client := NewServiceClient(hub, server)
+	hub.connect <- client
+	go client.run()
+
+	client.abort(CloseEvent{Code: websocket.ClosePolicyViolation, Reason: "bind uid failed", Initiator: CloseByServer})
+	<-client.closed
+	peer.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, _, err := peer.ReadMessage(); !websocket.IsCloseError(err, websocket.ClosePolicyViolation) {
+		t.Errorf("close frame got = %v", err)
+	}
+	select {
+	case id := <-app.closed:
+		t.Errorf("OnClose(%v) called for aborted client", id)
+	default:
+	}
+}
+
+func TestCloseCode(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		code int
+		want int
+	}{
+		{websocket.CloseNormalClosure, websocket.CloseNormalClosure},
+		{websocket.CloseGoingAway, websocket.CloseGoingAway},
+		{websocket.CloseUnsupportedData, websocket.CloseUnsupportedData},
+		{websocket.CloseInvalidFramePayloadData, websocket.CloseInvalidFramePayloadData},
+		{websocket.ClosePolicyViolation, websocket.ClosePolicyViolation},
+		{websocket.CloseInternalServerErr, websocket.CloseInternalServerErr},
+		{3000, 3000},
+		{CloseSlowConsumer, CloseSlowConsumer},
+		{4999, 4999},
+		{0, websocket.CloseNormalClosure},
+		{999, websocket.CloseNormalClosure},
+		{1004, websocket.CloseNormalClosure},
+		{websocket.CloseNoStatusReceived, websocket.CloseNormalClosure},
+		{websocket.CloseAbnormalClosure, websocket.CloseNormalClosure},
+		{websocket.CloseTLSHandshake, websocket.CloseNormalClosure},
+		{5000, websocket.CloseNormalClosure},
+	}
+	for _, tt := range tests {
+		if got := closeCode(tt.code); got != tt.want {
+			t.Errorf("closeCode(%v) got = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestClient_closeSlowConsumer(t *testing.T) {
+	t.Parallel()
+	hub, _ := newResumeHub(0)
+	server, peer := newTestConn(t)
+	client := NewServiceClient(hub, server)
+	// 没有启动write，调用flush会一直等待
+	client.conn = server
+	client.connDone = make(chan struct{})
+	client.writerDone = make(chan struct{})
+	client.send <- []byte("queued")
+	client.closeEvent = CloseEvent{Code: CloseSlowConsumer, Reason: "slow consumer", Initiator: CloseBySlowConsumer}
+
+	go client.close()
+	select {
+	case <-client.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("close() flushed the queue of a slow consumer")
+	}
+	peer.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, message, err := peer.ReadMessage(); !websocket.IsCloseError(err, CloseSlowConsumer) {
+		t.Errorf("ReadMessage() got = %s, err = %v, want close frame", message, err)
+	}
+}
@@ -0,0 +1,397 @@
+// wsctl 集群管理工具，通过register获取所有service，再调用各service的rpc接口。
+//
+//	wsctl -register localhost:8101 nodes
+//	wsctl -register localhost:8101 -o json uid 1001
+//	wsctl -register localhost:8101 send group room1 "hello"
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/bin-x/websocket"
+	pb "github.com/bin-x/websocket/proto"
+	"google.golang.org/grpc"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const usage = `usage: wsctl [flags] <command> [args]
+
+commands:
+  nodes                          列出所有service及其client数目
+  count                          统计所有client数目
+  client <clientId>              查看某个client
+  uid <uid>                      查看某个uid的所有client
+  group <group>                  查看某个分组的所有client
+  send client <clientId> <msg>   发送消息给某个client
+  send uid <uid> <msg>           发送消息给某个uid
+  send group <group> <msg>       发送消息给某个分组
+  send all <msg>                 发送消息给所有client
+  close <clientId>...            关闭client
+  disband <group>                解散分组
+
+flags:
+`
+
+func main() {
+	register := flag.String("register", "localhost:8101", "register地址")
+	output := flag.String("o", "table", "输出格式：table或json")
+	timeout := flag.Duration("timeout", 5*time.Second, "每次调用的超时时间")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 || (*output != "table" && *output != "json") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	c := &cluster{register: *register, timeout: *timeout, conns: make(map[string]*grpc.ClientConn)}
+	defer c.close()
+	result, err := c.run(flag.Arg(0), flag.Args()[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+	if *output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(result)
+	} else {
+		result.table(os.Stdout)
+	}
+	// 任意一个service执行失败时输出到标准错误并返回非0
+	if partial, ok := result.(partialResult); ok {
+		if failed := partial.failures(); len(failed) > 0 {
+			for _, node := range failed {
+				fmt.Fprintf(os.Stderr, "error: %v: %v\n", node.Addr, node.Error)
+			}
+			c.close()
+			os.Exit(1)
+		}
+	}
+}
+
+// 命令的输出
+type result interface {
+	table(w io.Writer)
+}
+
+// 调用了多个service的输出，返回执行失败的service
+type partialResult interface {
+	failures() nodeResults
+}
+
+type cluster struct {
+	register string
+	timeout  time.Duration
+	conns    map[string]*grpc.ClientConn
+}
+
+func (c *cluster) run(command string, args []string) (result, error) {
+	switch command {
+	case "nodes":
+		return c.nodes()
+	case "count":
+		nodes, err := c.nodes()
+		if err != nil {
+			return nil, err
+		}
+		total := countResult{failed: nodes.failures()}
+		for _, node := range nodes {
+			total.Clients += node.Clients
+		}
+		return total, nil
+	case "client":
+		if len(args) != 1 {
+			return nil, errors.New("usage: client <clientId>")
+		}
+		addr, err := nodeOfClientId(args[0])
+		if err != nil {
+			return nil, err
+		}
+		response, err := c.call(addr, func(ctx context.Context, api pb.ServiceApiClient) (*pb.ServiceResponse, error) {
+			return api.GetClientSession(ctx, &pb.ServiceRequest{ClientId: args[0]})
+		})
+		if err != nil {
+			return nil, err
+		}
+		return sessionsResult(response.Clients), nil
+	case "uid", "group":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("usage: %v <%v>", command, command)
+		}
+		responses, err := c.callAll(func(ctx context.Context, api pb.ServiceApiClient) (*pb.ServiceResponse, error) {
+			if command == "uid" {
+				return api.GetSessionsByUid(ctx, &pb.ServiceRequest{Uid: args[0]})
+			}
+			return api.GetSessionsByGroup(ctx, &pb.ServiceRequest{Group: args[0]})
+		})
+		if err != nil {
+			return nil, err
+		}
+		sessions := clusterSessions{failed: responses.failures()}
+		for _, response := range responses {
+			if response.response != nil {
+				sessions.sessions = append(sessions.sessions, response.response.Clients...)
+			}
+		}
+		return sessions, nil
+	case "send":
+		return c.send(args)
+	case "close":
+		if len(args) == 0 {
+			return nil, errors.New("usage: close <clientId>...")
+		}
+		var results nodeResults
+		for _, clientId := range args {
+			addr, err := nodeOfClientId(clientId)
+			if err != nil {
+				return nil, err
+			}
+			_, err = c.call(addr, func(ctx context.Context, api pb.ServiceApiClient) (*pb.ServiceResponse, error) {
+				return api.CloseClient(ctx, &pb.ServiceRequest{ClientId: clientId})
+			})
+			results = append(results, newNodeResult(addr, err))
+		}
+		return results, nil
+	case "disband":
+		if len(args) != 1 {
+			return nil, errors.New("usage: disband <group>")
+		}
+		return c.callAll(func(ctx context.Context, api pb.ServiceApiClient) (*pb.ServiceResponse, error) {
+			return api.DisbandGroup(ctx, &pb.ServiceRequest{Group: args[0]})
+		})
+	}
+	return nil, fmt.Errorf("unknown command %q", command)
+}
+
+func (c *cluster) send(args []string) (result, error) {
+	if len(args) == 2 && args[0] == "all" {
+		return c.callAll(func(ctx context.Context, api pb.ServiceApiClient) (*pb.ServiceResponse, error) {
+			return api.SendToAll(ctx, &pb.ServiceRequest{Message: []byte(args[1])})
+		})
+	}
+	if len(args) != 3 {
+		return nil, errors.New("usage: send client|uid|group <target> <msg>, send all <msg>")
+	}
+	target, message := args[1], []byte(args[2])
+	switch args[0] {
+	case "client":
+		addr, err := nodeOfClientId(target)
+		if err != nil {
+			return nil, err
+		}
+		response, err := c.call(addr, func(ctx context.Context, api pb.ServiceApiClient) (*pb.ServiceResponse, error) {
+			return api.SendToClient(ctx, &pb.ServiceRequest{ClientId: target, Message: message})
+		})
+		if err == nil && !response.Success {
+			err = errors.New("client not found")
+		}
+		return nodeResults{newNodeResult(addr, err)}, nil
+	case "uid":
+		return c.callAll(func(ctx context.Context, api pb.ServiceApiClient) (*pb.ServiceResponse, error) {
+			return api.SendToUid(ctx, &pb.ServiceRequest{Uid: target, Message: message})
+		})
+	case "group":
+		return c.callAll(func(ctx context.Context, api pb.ServiceApiClient) (*pb.ServiceResponse, error) {
+			return api.SendToGroup(ctx, &pb.ServiceRequest{Group: target, Message: message})
+		})
+	}
+	return nil, fmt.Errorf("unknown send target %q", args[0])
+}
+
+func (c *cluster) nodes() (nodesResult, error) {
+	responses, err := c.callAll(func(ctx context.Context, api pb.ServiceApiClient) (*pb.ServiceResponse, error) {
+		return api.GetAllClientCount(ctx, &pb.ServiceRequest{})
+	})
+	if err != nil {
+		return nil, err
+	}
+	nodes := make(nodesResult, 0, len(responses))
+	for _, response := range responses {
+		node := nodeInfo{Addr: response.Addr, Error: response.Error}
+		if response.response != nil {
+			node.Clients = int(response.response.Count)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+func (c *cluster) conn(addr string) (*grpc.ClientConn, error) {
+	if conn, ok := c.conns[addr]; ok {
+		return conn, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, addr, grpc.WithInsecure(), grpc.WithBlock())
+	if err != nil {
+		return nil, err
+	}
+	c.conns[addr] = conn
+	return conn, nil
+}
+
+func (c *cluster) call(addr string, fn func(ctx context.Context, api pb.ServiceApiClient) (*pb.ServiceResponse, error)) (*pb.ServiceResponse, error) {
+	conn, err := c.conn(addr)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	return fn(ctx, pb.NewServiceApiClient(conn))
+}
+
+// 调用所有service，单个service出错时记录在结果中
+func (c *cluster) callAll(fn func(ctx context.Context, api pb.ServiceApiClient) (*pb.ServiceResponse, error)) (nodeResults, error) {
+	addresses, err := websocket.ListServiceAddresses(c.register, c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(addresses)
+	results := make(nodeResults, 0, len(addresses))
+	for _, addr := range addresses {
+		response, err := c.call(addr, fn)
+		result := newNodeResult(addr, err)
+		result.response = response
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (c *cluster) close() {
+	for _, conn := range c.conns {
+		conn.Close()
+	}
+}
+
+func nodeOfClientId(clientId string) (string, error) {
+	ip, port, _, err := websocket.ClientIdToAddress(clientId)
+	if err != nil {
+		return "", err
+	}
+	return ip + ":" + strconv.FormatUint(uint64(port), 10), nil
+}
+
+type nodeResult struct {
+	Addr     string `json:"addr"`
+	Ok       bool   `json:"ok"`
+	Error    string `json:"error,omitempty"`
+	response *pb.ServiceResponse
+}
+
+func newNodeResult(addr string, err error) nodeResult {
+	result := nodeResult{Addr: addr, Ok: err == nil}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+type nodeResults []nodeResult
+
+func (r nodeResults) failures() nodeResults {
+	var failed nodeResults
+	for _, result := range r {
+		if !result.Ok {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+func (r nodeResults) table(f io.Writer) {
+	w := tabwriter.NewWriter(f, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tOK\tERROR")
+	for _, result := range r {
+		fmt.Fprintf(w, "%v\t%v\t%v\n", result.Addr, result.Ok, result.Error)
+	}
+	w.Flush()
+}
+
+type nodeInfo struct {
+	Addr    string `json:"addr"`
+	Clients int    `json:"clients"`
+	Error   string `json:"error,omitempty"`
+}
+
+type nodesResult []nodeInfo
+
+func (r nodesResult) failures() nodeResults {
+	var failed nodeResults
+	for _, node := range r {
+		if node.Error != "" {
+			failed = append(failed, nodeResult{Addr: node.Addr, Error: node.Error})
+		}
+	}
+	return failed
+}
+
+func (r nodesResult) table(f io.Writer) {
+	w := tabwriter.NewWriter(f, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tCLIENTS\tERROR")
+	for _, node := range r {
+		fmt.Fprintf(w, "%v\t%v\t%v\n", node.Addr, node.Clients, node.Error)
+	}
+	w.Flush()
+}
+
+type countResult struct {
+	Clients int `json:"clients"`
+	failed  nodeResults
+}
+
+func (r countResult) table(f io.Writer) {
+	fmt.Fprintln(f, r.Clients)
+}
+
+func (r countResult) failures() nodeResults {
+	return r.failed
+}
+
+type sessionsResult []*pb.Client
+
+func (r sessionsResult) table(f io.Writer) {
+	w := tabwriter.NewWriter(f, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "CLIENT\tUID\tGROUPS\tNODE\tCONNECTED\tREMOTE\tINFO")
+	for _, client := range r {
+		info := make([]string, 0, len(client.Info))
+		for k, v := range client.Info {
+			info = append(info, k+"="+v)
+		}
+		sort.Strings(info)
+		sort.Strings(client.Group)
+		connected := time.Unix(client.ConnectTime, 0).Format("2006-01-02 15:04:05")
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n", client.Id, client.Uid, strings.Join(client.Group, ","),
+			client.Node, connected, client.RemoteAddr, strings.Join(info, ","))
+	}
+	w.Flush()
+}
+
+// 所有service上的client，以及查询失败的service
+type clusterSessions struct {
+	sessions sessionsResult
+	failed   nodeResults
+}
+
+func (r clusterSessions) table(f io.Writer) {
+	r.sessions.table(f)
+}
+
+func (r clusterSessions) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.sessions)
+}
+
+func (r clusterSessions) failures() nodeResults {
+	return r.failed
+}
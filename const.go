@@ -5,6 +5,18 @@ import "time"
 const (
 	registerActionConnect       = "connect"
 	registerActionBroadcastAddr = "broadcast_addresses"
+
+	// service上报本地uid的变化
+	registerActionUidOnline  = "uid_online"
+	registerActionUidOffline = "uid_offline"
+	registerActionSyncUids   = "sync_uids"
+
+	// register广播集群范围内uid的上下线
+	registerActionBroadcastOnline  = "broadcast_online"
+	registerActionBroadcastOffline = "broadcast_offline"
+
+	// 管理工具查询所有service的地址，register只回复给该连接
+	registerActionListAddresses = "list_addresses"
 )
 
 const (
@@ -22,4 +34,7 @@ const (
 	pingPeriod = (pongWait * 9) / 10
 	// Maximum message size allowed from peer.
 	maxMessageSize = 512
+
+	// Maximum message size allowed from service, sync_uids carries all uids of a service.
+	registerMaxMessageSize = 16 << 20
 )
@@ -0,0 +1,53 @@
+package websocket
+
+import "encoding/json"
+
+// 内置的json消息格式：{"type":"xxx","id":"xxx","data":{...}}
+// 出错时：{"type":"error","id":"xxx","error":{"code":"xxx","message":"xxx"}}
+type Envelope struct {
+	Type  string          `json:"type"`
+	Id    string          `json:"id,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+	Error *EnvelopeError  `json:"error,omitempty"`
+}
+
+// 返回给客户端的错误，handler返回该类型时使用其中的code
+type EnvelopeError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *EnvelopeError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+// 内置的消息类型
+const (
+	envelopeTypeError = "error"
+	// 开启会话恢复时的会话信息
+	EnvelopeTypeSession = "session"
+	// 可靠发送的消息和客户端的确认
+	EnvelopeTypeReliable = "reliable"
+	EnvelopeTypeAck      = "ack"
+	// RequestClient的请求和客户端的回复
+	EnvelopeTypeRequest  = "request"
+	EnvelopeTypeResponse = "response"
+)
+
+// 将data编码为消息
+func MarshalEnvelope(msgType, id string, data interface{}) ([]byte, error) {
+	envelope := Envelope{Type: msgType, Id: id}
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		envelope.Data = raw
+	}
+	return json.Marshal(envelope)
+}
+
+func marshalErrorEnvelope(id string, err *EnvelopeError) []byte {
+	message, _ := json.Marshal(Envelope{Type: envelopeTypeError, Id: id, Error: err})
+	return message
+}
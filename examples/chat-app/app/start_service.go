@@ -20,6 +20,9 @@ func main() {
 	// websocket的监听地址，供客户端访问。注意检查端口是否能够正常访问
 	wsAddr := ":9201"
 
-	hub := NewServiceHub(registerAddr, rpcPort, lanIp, &App{})
+	// 允许连接的网页地址，其他网站无法使用用户的cookie建立连接
+	origins := OriginPolicy{Origins: []string{"http://localhost:8082", "http://127.0.0.1:8082"}}
+
+	hub := NewServiceHub(registerAddr, rpcPort, lanIp, &App{}, WithOriginPolicy(origins))
 	hub.Start(wsAddr)
 }
@@ -20,6 +20,9 @@ func main() {
 	// websocket的监听地址，供客户端访问。注意检查端口是否能够正常访问
 	wsAddr := ":9003"
 
-	hub := NewServiceHub(registerAddr, rpcPort, lanIp, &App{})
+	// 允许连接的网页地址，如 "https://*.example.com"，未设置时只允许与websocket地址相同的来源
+	origins := OriginPolicy{Origins: []string{}}
+
+	hub := NewServiceHub(registerAddr, rpcPort, lanIp, &App{}, WithOriginPolicy(origins))
 	hub.Start(wsAddr)
 }
@@ -0,0 +1,334 @@
+package websocket
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	pb "github.com/bin-x/websocket/proto"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// 分组的一条历史消息，seq由每个服务各自生成，同一服务内递增
+type HistoryMessage struct {
+	Seq     uint64    `json:"seq"`
+	Time    time.Time `json:"time"`
+	Message []byte    `json:"message"`
+}
+
+// 分组历史消息的持久化存储，每个服务使用各自的存储
+type HistoryStore interface {
+	Append(group string, message HistoryMessage) error
+	// 读取最近的limit条消息，按seq从小到大排列
+	Load(group string, limit int) ([]HistoryMessage, error)
+}
+
+// HistoryStore实现该接口时，解散分组会删除其历史消息
+type HistoryRemover interface {
+	Remove(group string) error
+}
+
+const (
+	// 内存中最多保留的分组数，超出时移除最久未使用的分组，有store时再次访问会重新加载
+	maxHistoryGroups = 10000
+	// 文件中每个分组默认最多保留的消息数
+	defaultHistoryFileMessages = 10000
+)
+
+// 开启分组历史消息，每个分组在内存中保留最近的size条，store为nil时不持久化。
+// 每个服务都会收到SendToGroup，因此各自保存完整的历史消息。
+// 内存中最多保留10000个分组，超出时移除最久未使用的分组。
+func WithGroupHistory(size int, store HistoryStore) ServiceOption {
+	return func(sh *ServiceHub) {
+		sh.history = &groupHistory{size: size, store: store, groups: make(map[string]*historyRing)}
+	}
+}
+
+// 读取历史消息时的条件，多个条件同时生效
+type ReplayOption func(replay *pb.Replay)
+
+// 最近的n条消息
+func ReplayLast(n int) ReplayOption {
+	return func(replay *pb.Replay) {
+		replay.Last = int32(n)
+	}
+}
+
+// t之后的消息
+func ReplaySince(t time.Time) ReplayOption {
+	return func(replay *pb.Replay) {
+		replay.Since = t.UnixNano()
+	}
+}
+
+// 序号大于seq的消息
+func ReplaySinceSeq(seq uint64) ReplayOption {
+	return func(replay *pb.Replay) {
+		replay.SinceSeq = seq
+	}
+}
+
+func newReplay(options []ReplayOption) *pb.Replay {
+	replay := &pb.Replay{}
+	for _, option := range options {
+		option(replay)
+	}
+	return replay
+}
+
+type groupHistory struct {
+	mu     sync.Mutex
+	size   int
+	store  HistoryStore
+	groups map[string]*historyRing
+	// 所有分组共用，移除的分组再次使用时seq不会重复
+	seq    uint64
+	logger Logger
+}
+
+// 固定大小的环形缓冲区
+type historyRing struct {
+	messages []HistoryMessage
+	start    int
+	lastUsed time.Time
+}
+
+func (r *historyRing) append(message HistoryMessage, size int) {
+	if len(r.messages) < size {
+		r.messages = append(r.messages, message)
+		return
+	}
+	r.messages[r.start] = message
+	r.start = (r.start + 1) % size
+}
+
+// 按时间顺序返回所有消息
+func (r *historyRing) all() []HistoryMessage {
+	messages := make([]HistoryMessage, 0, len(r.messages))
+	messages = append(messages, r.messages[r.start:]...)
+	return append(messages, r.messages[:r.start]...)
+}
+
+// 获取分组的缓冲区，不在内存中时从store加载。
+// create为false且分组没有历史消息时返回nil，不占用内存。
+func (h *groupHistory) ring(group string, create bool) *historyRing {
+	now := time.Now()
+	if r, ok := h.groups[group]; ok {
+		r.lastUsed = now
+		return r
+	}
+	r := &historyRing{lastUsed: now}
+	if h.store != nil {
+		messages, err := h.store.Load(group, h.size)
+		if err != nil {
+			logTo(h.logger, LogError, "load group history error", F("group", group), F("error", err))
+		}
+		for _, message := range messages {
+			r.append(message, h.size)
+			if message.Seq > h.seq {
+				h.seq = message.Seq
+			}
+		}
+	}
+	if len(r.messages) == 0 && !create {
+		return nil
+	}
+	if len(h.groups) >= maxHistoryGroups {
+		h.evict()
+	}
+	h.groups[group] = r
+	return r
+}
+
+// 移除最久未使用的分组
+func (h *groupHistory) evict() {
+	var oldest string
+	var oldestTime time.Time
+	for group, r := range h.groups {
+		if oldest == "" || r.lastUsed.Before(oldestTime) {
+			oldest, oldestTime = group, r.lastUsed
+		}
+	}
+	delete(h.groups, oldest)
+}
+
+func (h *groupHistory) record(group string, message []byte) {
+	if h == nil || h.size <= 0 {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	r := h.ring(group, true)
+	h.seq++
+	m := HistoryMessage{Seq: h.seq, Time: time.Now(), Message: message}
+	r.append(m, h.size)
+	if h.store != nil {
+		if err := h.store.Append(group, m); err != nil {
+			logTo(h.logger, LogError, "append group history error", F("group", group), F("error", err))
+		}
+	}
+}
+
+func (h *groupHistory) query(group string, replay *pb.Replay) []HistoryMessage {
+	if h == nil || h.size <= 0 {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	r := h.ring(group, false)
+	if r == nil {
+		return nil
+	}
+	var messages []HistoryMessage
+	for _, m := range r.all() {
+		if m.Seq <= replay.SinceSeq || m.Time.UnixNano() < replay.Since {
+			continue
+		}
+		messages = append(messages, m)
+	}
+	if replay.Last > 0 && len(messages) > int(replay.Last) {
+		messages = messages[len(messages)-int(replay.Last):]
+	}
+	return messages
+}
+
+// 解散分组时删除其历史消息
+func (h *groupHistory) remove(group string) {
+	if h == nil || h.size <= 0 {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.groups, group)
+	if remover, ok := h.store.(HistoryRemover); ok {
+		if err := remover.Remove(group); err != nil {
+			logTo(h.logger, LogError, "remove group history error", F("group", group), F("error", err))
+		}
+	}
+}
+
+// 保存在文件中的历史消息，每个分组一个文件，每行一条消息
+type fileHistoryStore struct {
+	mu          sync.Mutex
+	dir         string
+	maxMessages int
+	// 每个分组文件中的消息数，第一次写入时统计
+	lines map[string]int
+}
+
+// 每个分组的文件最多保留maxMessages条消息，超过两倍时重写文件只保留最近的maxMessages条，
+// maxMessages<=0时为10000
+func NewFileHistoryStore(dir string, maxMessages int) (HistoryStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	if maxMessages <= 0 {
+		maxMessages = defaultHistoryFileMessages
+	}
+	return &fileHistoryStore{dir: dir, maxMessages: maxMessages, lines: make(map[string]int)}, nil
+}
+
+func (s *fileHistoryStore) path(group string) string {
+	return filepath.Join(s.dir, hex.EncodeToString([]byte(group))+".log")
+}
+
+func (s *fileHistoryStore) Append(group string, message HistoryMessage) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lines, ok := s.lines[group]
+	if !ok {
+		messages, err := s.load(group, 0)
+		if err != nil {
+			return err
+		}
+		lines = len(messages)
+	}
+	f, err := os.OpenFile(s.path(group), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	f.Close()
+	if err != nil {
+		return err
+	}
+	s.lines[group] = lines + 1
+	if lines+1 > 2*s.maxMessages {
+		return s.compact(group)
+	}
+	return nil
+}
+
+// 只保留最近的maxMessages条，先写临时文件再重命名
+func (s *fileHistoryStore) compact(group string) error {
+	messages, err := s.load(group, s.maxMessages)
+	if err != nil {
+		return err
+	}
+	var data []byte
+	for _, m := range messages {
+		line, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+	tmp := s.path(group) + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path(group)); err != nil {
+		return err
+	}
+	s.lines[group] = len(messages)
+	return nil
+}
+
+func (s *fileHistoryStore) Remove(group string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.lines, group)
+	if err := os.Remove(s.path(group)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *fileHistoryStore) Load(group string, limit int) ([]HistoryMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(group, limit)
+}
+
+func (s *fileHistoryStore) load(group string, limit int) ([]HistoryMessage, error) {
+	f, err := os.Open(s.path(group))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var messages []HistoryMessage
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for scanner.Scan() {
+		var m HistoryMessage
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			continue
+		}
+		messages = append(messages, m)
+		if limit > 0 && len(messages) > limit {
+			messages = messages[1:]
+		}
+	}
+	return messages, scanner.Err()
+}
@@ -0,0 +1,99 @@
+package websocket
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestGroupHistory_remove(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "group_history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := NewFileHistoryStore(dir, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &groupHistory{size: 10, store: store, groups: make(map[string]*historyRing)}
+
+	h.record(groupString, []byte("1"))
+	h.remove(groupString)
+	if len(h.groups) != 0 {
+		t.Errorf("remove() groups got = %v, want empty", len(h.groups))
+	}
+	// 文件也被删除，不会重新加载
+	if messages := h.query(groupString, newReplay(nil)); len(messages) != 0 {
+		t.Errorf("query() after remove got = %v, want empty", messages)
+	}
+	if messages, _ := store.Load(groupString, 10); len(messages) != 0 {
+		t.Errorf("Load() after remove got = %v, want empty", messages)
+	}
+
+	// 查询没有历史消息的分组不会占用内存
+	h.query("empty", newReplay(nil))
+	if _, ok := h.groups["empty"]; ok {
+		t.Errorf("query() created ring for empty group")
+	}
+}
+
+func TestGroupHistory_evict(t *testing.T) {
+	t.Parallel()
+	h := &groupHistory{size: 2, groups: make(map[string]*historyRing)}
+	for i := 0; i < maxHistoryGroups; i++ {
+		h.groups[strconv.Itoa(i)] = &historyRing{lastUsed: time.Now().Add(time.Duration(i) * time.Second)}
+	}
+	h.groups["0"].lastUsed = time.Now().Add(time.Hour)
+
+	h.record(groupString, []byte("1"))
+	if len(h.groups) != maxHistoryGroups {
+		t.Errorf("record() groups got = %v, want %v", len(h.groups), maxHistoryGroups)
+	}
+	if _, ok := h.groups["1"]; ok {
+		t.Errorf("record() didn't evict the least recently used group")
+	}
+
+	// 移除的分组重新使用时seq继续递增
+	h.remove(groupString)
+	h.record(groupString, []byte("2"))
+	if messages := h.query(groupString, newReplay(nil)); len(messages) != 1 || messages[0].Seq != 2 {
+		t.Errorf("query() after evict got = %v, want seq 2", messages)
+	}
+}
+
+func TestFileHistoryStore_compact(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "group_history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := NewFileHistoryStore(dir, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 5; i++ {
+		if err := store.Append(groupString, HistoryMessage{Seq: uint64(i), Message: []byte(strconv.Itoa(i))}); err != nil {
+			t.Errorf("Append() error = %v", err)
+		}
+	}
+	// 第5条超过两倍，重写后只保留最近的2条
+	messages, err := store.(*fileHistoryStore).load(groupString, 0)
+	if err != nil || len(messages) != 2 || messages[0].Seq != 4 || messages[1].Seq != 5 {
+		t.Errorf("load() after compact got = %v, err = %v", messages, err)
+	}
+
+	// 重新打开时统计已有的消息数
+	store, _ = NewFileHistoryStore(dir, 2)
+	for i := 6; i <= 8; i++ {
+		store.Append(groupString, HistoryMessage{Seq: uint64(i)})
+	}
+	if messages, _ := store.(*fileHistoryStore).load(groupString, 0); len(messages) != 2 || messages[1].Seq != 8 {
+		t.Errorf("load() after reopen got = %v, want seq 7 and 8", messages)
+	}
+}
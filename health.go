@@ -0,0 +1,104 @@
+package websocket
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// 开启 /debug/sessions，请求需要带上 Authorization: Bearer token，未设置时不开启
+func WithAdminToken(token string) ServiceOption {
+	return func(sh *ServiceHub) {
+		sh.adminToken = token
+	}
+}
+
+// /readyz返回的状态
+type readiness struct {
+	Ready    bool `json:"ready"`
+	Register bool `json:"register"`
+	Rpc      bool `json:"rpc"`
+	Draining bool `json:"draining"`
+}
+
+// /debug/sessions返回的client信息
+type debugSession struct {
+	Id          string            `json:"id"`
+	Uid         string            `json:"uid"`
+	Groups      []string          `json:"groups"`
+	Info        map[string]string `json:"info"`
+	QueueDepth  int               `json:"queue_depth"`
+	ConnectTime int64             `json:"connect_time"`
+	RemoteAddr  string            `json:"remote_addr"`
+}
+
+// 准备下线，之后/readyz返回503，负载均衡不再转发新的连接，已有的连接不受影响
+func (sh *ServiceHub) Drain() {
+	atomic.StoreInt32(&sh.draining, 1)
+}
+
+func (sh *ServiceHub) readiness() readiness {
+	r := readiness{
+		Register: atomic.LoadInt32(&sh.registerConnected) == 1,
+		Rpc:      atomic.LoadInt32(&sh.rpcServing) == 1,
+		Draining: atomic.LoadInt32(&sh.draining) == 1,
+	}
+	r.Ready = r.Register && r.Rpc && !r.Draining
+	return r
+}
+
+// 进程存活即返回200
+func (sh *ServiceHub) serveHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("ok"))
+}
+
+// 已连接到register、rpc服务已开启且未调用Drain时返回200，否则返回503
+func (sh *ServiceHub) serveReadyz(w http.ResponseWriter, r *http.Request) {
+	state := sh.readiness()
+	w.Header().Set("Content-Type", "application/json")
+	if !state.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(state)
+}
+
+func (sh *ServiceHub) serveDebugSessions(w http.ResponseWriter, r *http.Request) {
+	if sh.adminToken == "" {
+		http.NotFound(w, r)
+		return
+	}
+	authorization := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, "Bearer ") ||
+		subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(authorization, "Bearer ")), []byte(sh.adminToken)) != 1 {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sh.debugSessions())
+}
+
+// 由hub的run获取所有client，再由各client的run生成会话信息
+func (sh *ServiceHub) debugSessions() []debugSession {
+	reply := make(chan []*Client)
+	sh.getClients <- reply
+	clients := <-reply
+	sessions := make([]debugSession, 0, len(clients))
+	for _, client := range clients {
+		session := client.snapshot()
+		if session == nil {
+			continue
+		}
+		sessions = append(sessions, debugSession{
+			Id:          session.Id,
+			Uid:         session.Uid,
+			Groups:      session.Group,
+			Info:        session.Info,
+			QueueDepth:  len(client.send),
+			ConnectTime: session.ConnectTime,
+			RemoteAddr:  session.RemoteAddr,
+		})
+	}
+	return sessions
+}
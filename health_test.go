@@ -0,0 +1,76 @@
+package websocket
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServiceHub_serveDebugSessions_auth(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		adminToken    string
+		authorization string
+		want          int
+	}{
+		{"disabled", "", "Bearer ", http.StatusNotFound},
+		{"missing token", "secret", "", http.StatusUnauthorized},
+		{"wrong token", "secret", "Bearer wrong", http.StatusUnauthorized},
+		{"without bearer", "secret", "secret", http.StatusUnauthorized},
+		{"lowercase bearer", "secret", "bearer secret", http.StatusUnauthorized},
+		{"valid", "secret", "Bearer secret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := CreateHub()
+			hub.adminToken = tt.adminToken
+			r := httptest.NewRequest("GET", "/debug/sessions", nil)
+			r.Header.Set("Authorization", tt.authorization)
+			w := httptest.NewRecorder()
+			hub.serveDebugSessions(w, r)
+			if w.Code != tt.want {
+				t.Errorf("serveDebugSessions() got = %v, want %v", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceHub_debugSessions(t *testing.T) {
+	t.Parallel()
+	hub := CreateHub()
+	hub.clients["1"].send <- []byte("pending")
+	sessions := hub.debugSessions()
+	if len(sessions) != 2 {
+		t.Fatalf("debugSessions() got %v sessions, want 2", len(sessions))
+	}
+	for _, session := range sessions {
+		if session.Id == "1" && (session.Uid != uid1 || session.QueueDepth != 1 || session.Info["age"] != "11") {
+			t.Errorf("debugSessions() got = %+v", session)
+		}
+	}
+}
+
+func TestServiceHub_serveReadyz(t *testing.T) {
+	t.Parallel()
+	hub := CreateHub()
+	w := httptest.NewRecorder()
+	hub.serveReadyz(w, httptest.NewRequest("GET", "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("serveReadyz() before start got = %v, want 503", w.Code)
+	}
+	hub.registerConnected, hub.rpcServing = 1, 1
+	w = httptest.NewRecorder()
+	hub.serveReadyz(w, httptest.NewRequest("GET", "/readyz", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("serveReadyz() got = %v, want 200", w.Code)
+	}
+
+	hub.Drain()
+	w = httptest.NewRecorder()
+	hub.serveReadyz(w, httptest.NewRequest("GET", "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), `"draining":true`) {
+		t.Errorf("serveReadyz() after Drain got = %v %v, want 503", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,81 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+var (
+	ErrClientNotFound = errors.New("client not found")
+	// CompareAndSetInfo时info已被其他地方修改
+	ErrInfoConflict = errors.New("info version conflict")
+)
+
+const (
+	infoActionSet           = "set"
+	infoActionUpdate        = "update"
+	infoActionCompareAndSet = "compare_and_set"
+	infoActionDeleteKeys    = "delete_keys"
+)
+
+// 对client的info的修改，由client的run依次执行
+type infoOp struct {
+	action  string
+	info    map[string]string
+	keys    []string
+	version uint64
+	reply   chan infoResult
+}
+
+type infoResult struct {
+	version  uint64
+	conflict bool
+}
+
+// 执行info的修改，成功后版本号加1
+func (c *Client) applyInfoOp(op *infoOp) infoResult {
+	switch op.action {
+	case infoActionCompareAndSet:
+		if op.version != c.infoVersion {
+			return infoResult{version: c.infoVersion, conflict: true}
+		}
+		fallthrough
+	case infoActionSet:
+		c.info = make(map[string]string, len(op.info))
+		for k, v := range op.info {
+			c.info[k] = v
+		}
+	case infoActionUpdate:
+		for k, v := range op.info {
+			c.info[k] = v
+		}
+	case infoActionDeleteKeys:
+		for _, key := range op.keys {
+			delete(c.info, key)
+		}
+	}
+	c.infoVersion++
+	c.hub.infoIndex.update(c, c.info)
+	return infoResult{version: c.infoVersion}
+}
+
+// 将结构化的值编码为info中的字符串，字符串保持原样，其他类型使用json编码
+func EncodeInfoValue(value interface{}) (string, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// 将info中的字符串解码到value，value需为指针
+func DecodeInfoValue(raw string, value interface{}) error {
+	if s, ok := value.(*string); ok {
+		*s = raw
+		return nil
+	}
+	return json.Unmarshal([]byte(raw), value)
+}
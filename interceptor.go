@@ -0,0 +1,126 @@
+package websocket
+
+import (
+	"context"
+	pb "github.com/bin-x/websocket/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 调用方在metadata中附带自己的rpc地址
+const metadataCallerKey = "x-websocket-caller"
+
+// ServiceApi调用某个服务时的信息
+type CallInfo struct {
+	// 目标服务的rpc地址
+	Node string
+	// 方法名，如 SendToAll
+	Method string
+	// 目标为本服务，不经过grpc
+	Local bool
+}
+
+// 收到其他服务（或本服务）调用时的信息
+type HandlerInfo struct {
+	Method string
+	// 调用方的rpc地址
+	Caller string
+}
+
+type CallInvoker func(ctx context.Context, info *CallInfo, request *pb.ServiceRequest) (*pb.ServiceResponse, error)
+
+// 调用方的拦截器，调用invoker继续调用，不调用时直接返回结果。
+// 可以修改request，或通过 metadata.AppendToOutgoingContext 传递metadata给被调用方。
+type CallInterceptor func(ctx context.Context, info *CallInfo, request *pb.ServiceRequest, invoker CallInvoker) (*pb.ServiceResponse, error)
+
+type Handler func(ctx context.Context, info *HandlerInfo, request *pb.ServiceRequest) (*pb.ServiceResponse, error)
+
+// 被调用方的拦截器，可以通过 metadata.FromIncomingContext 获取调用方传递的metadata
+type HandlerInterceptor func(ctx context.Context, info *HandlerInfo, request *pb.ServiceRequest, handler Handler) (*pb.ServiceResponse, error)
+
+// 添加调用方拦截器，按添加顺序执行，第一个在最外层
+func WithCallInterceptors(interceptors ...CallInterceptor) ServiceOption {
+	return func(sh *ServiceHub) {
+		sh.callInterceptors = append(sh.callInterceptors, interceptors...)
+	}
+}
+
+// 添加被调用方拦截器，按添加顺序执行，第一个在最外层
+func WithHandlerInterceptors(interceptors ...HandlerInterceptor) ServiceOption {
+	return func(sh *ServiceHub) {
+		sh.handlerInterceptors = append(sh.handlerInterceptors, interceptors...)
+	}
+}
+
+func chainCall(interceptors []CallInterceptor, invoker CallInvoker) CallInvoker {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoker
+		invoker = func(ctx context.Context, info *CallInfo, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
+			return interceptor(ctx, info, request, next)
+		}
+	}
+	return invoker
+}
+
+func chainHandler(interceptors []HandlerInterceptor, handler Handler) Handler {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], handler
+		handler = func(ctx context.Context, info *HandlerInfo, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
+			return interceptor(ctx, info, request, next)
+		}
+	}
+	return handler
+}
+
+// 实际发起调用，本服务直接调用rpcMethods，其他服务通过grpc
+func (sh *ServiceHub) invoke(ctx context.Context, info *CallInfo, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, metadataCallerKey, sh.rpcAddr())
+	if info.Local {
+		// 本地调用时将metadata转为被调用方收到的metadata
+		md, _ := metadata.FromOutgoingContext(ctx)
+		return sh.handle(metadata.NewIncomingContext(ctx, md), info.Method, request)
+	}
+	client, err := sh.getServiceConn(info.Node)
+	if err != nil {
+		return nil, err
+	}
+	return call(pb.NewServiceApiClient(client.conn), info.Method, ctx, request)
+}
+
+// 调用方的rpc地址，没有时返回空
+func callerOf(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if callers := md.Get(metadataCallerKey); len(callers) > 0 {
+			return callers[0]
+		}
+	}
+	return ""
+}
+
+// 经过被调用方拦截器后调用rpcMethods
+func (sh *ServiceHub) handle(ctx context.Context, method string, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
+	info := &HandlerInfo{Method: method, Caller: callerOf(ctx)}
+	handler := chainHandler(sh.handlerInterceptors, func(ctx context.Context, info *HandlerInfo, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
+		return call(sh.rm, info.Method, ctx, request)
+	})
+	return handler(ctx, info, request)
+}
+
+// grpc服务端拦截器，将grpc调用交给handle
+func (sh *ServiceHub) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	request, ok := req.(*pb.ServiceRequest)
+	if !ok || len(sh.handlerInterceptors) == 0 {
+		return handler(ctx, req)
+	}
+	return sh.handle(ctx, grpcMethodName(info.FullMethod), request)
+}
+
+// "/proto.ServiceApi/sendToAll" 转为 "SendToAll"
+func grpcMethodName(fullMethod string) string {
+	name := fullMethod[strings.LastIndexByte(fullMethod, '/')+1:]
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToUpper(r)) + name[size:]
+}
@@ -0,0 +1,72 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	pb "github.com/bin-x/websocket/proto"
+	"google.golang.org/grpc/metadata"
+	"testing"
+)
+
+func TestServiceApi_callNode_interceptors(t *testing.T) {
+	t.Parallel()
+	hub := CreateHub()
+	hub.lanIp, hub.rpcPort = "127.0.0.1", 8003
+	hub.rm = &rpcMethods{hub: hub}
+	var order []string
+	hub.callInterceptors = []CallInterceptor{
+		func(ctx context.Context, info *CallInfo, request *pb.ServiceRequest, invoker CallInvoker) (*pb.ServiceResponse, error) {
+			order = append(order, "call1")
+			ctx = metadata.AppendToOutgoingContext(ctx, "trace-id", "t1")
+			return invoker(ctx, info, request)
+		},
+		func(ctx context.Context, info *CallInfo, request *pb.ServiceRequest, invoker CallInvoker) (*pb.ServiceResponse, error) {
+			order = append(order, "call2")
+			if !info.Local || info.Node != "127.0.0.1:8003" {
+				t.Errorf("CallInfo got = %+v", info)
+			}
+			return invoker(ctx, info, request)
+		},
+	}
+	hub.handlerInterceptors = []HandlerInterceptor{
+		func(ctx context.Context, info *HandlerInfo, request *pb.ServiceRequest, handler Handler) (*pb.ServiceResponse, error) {
+			order = append(order, "handler")
+			md, _ := metadata.FromIncomingContext(ctx)
+			if got := md.Get("trace-id"); len(got) != 1 || got[0] != "t1" || info.Caller != "127.0.0.1:8003" {
+				t.Errorf("handler got metadata = %v, caller = %v", md, info.Caller)
+			}
+			if info.Method == "CloseClient" {
+				return nil, errors.New("forbidden")
+			}
+			return handler(ctx, info, request)
+		},
+	}
+	api := &ServiceApi{hub: hub}
+
+	tests := []struct {
+		name      string
+		method    string
+		wantCount int32
+		wantErr   bool
+	}{
+		{"pass through", "GetAllClientCount", 2, false},
+		{"short circuit", "CloseClient", 0, true},
+	}
+	for _, tt := range tests {
+		order = nil
+		response, err := api.callNode(hub.rpcAddr(), tt.method, context.Background(), &pb.ServiceRequest{ClientId: "1"})
+		if (err != nil) != tt.wantErr || (err == nil && response.Count != tt.wantCount) {
+			t.Errorf("%v callNode() got = %v, %v", tt.name, response, err)
+		}
+		if len(order) != 3 || order[0] != "call1" || order[1] != "call2" || order[2] != "handler" {
+			t.Errorf("%v interceptor order got = %v", tt.name, order)
+		}
+	}
+}
+
+func TestGrpcMethodName(t *testing.T) {
+	t.Parallel()
+	if got := grpcMethodName("/proto.ServiceApi/sendToAll"); got != "SendToAll" {
+		t.Errorf("grpcMethodName() got = %v", got)
+	}
+}
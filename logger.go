@@ -0,0 +1,112 @@
+package websocket
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// 日志级别
+type LogLevel int
+
+const (
+	LogDebug LogLevel = iota
+	LogInfo
+	LogWarn
+	LogError
+	// 不输出任何日志
+	LogSilent
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LogDebug:
+		return "DEBUG"
+	case LogInfo:
+		return "INFO"
+	case LogWarn:
+		return "WARN"
+	case LogError:
+		return "ERROR"
+	}
+	return "SILENT"
+}
+
+// 日志中的结构化字段
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// 日志接口，可以接入自己的日志系统
+type Logger interface {
+	Log(level LogLevel, msg string, fields ...Field)
+}
+
+// 设置service的日志，默认只输出错误日志到标准错误
+func WithLogger(logger Logger) ServiceOption {
+	return func(sh *ServiceHub) {
+		sh.logger = logger
+	}
+}
+
+// 设置register的日志，默认只输出错误日志到标准错误
+func WithRegisterLogger(logger Logger) RegisterOption {
+	return func(r *RegisterHub) {
+		r.logger = logger
+	}
+}
+
+// 输出到w，低于level的日志不输出，格式：2006/01/02 15:04:05 WARN msg key=value
+func NewStdLogger(w io.Writer, level LogLevel) Logger {
+	return &stdLogger{logger: log.New(w, "", log.LstdFlags), level: level}
+}
+
+func defaultLogger() Logger {
+	return NewStdLogger(os.Stderr, LogError)
+}
+
+type stdLogger struct {
+	logger *log.Logger
+	level  LogLevel
+}
+
+func (l *stdLogger) Log(level LogLevel, msg string, fields ...Field) {
+	if level < l.level {
+		return
+	}
+	var b strings.Builder
+	b.WriteString(level.String())
+	b.WriteByte(' ')
+	b.WriteString(msg)
+	for _, field := range fields {
+		fmt.Fprintf(&b, " %s=%v", field.Key, field.Value)
+	}
+	l.logger.Output(2, b.String())
+}
+
+// 记录错误并退出，用于无法继续运行的情况
+func fatal(logger Logger, msg string, err error) {
+	logTo(logger, LogError, msg, F("error", err))
+	os.Exit(1)
+}
+
+func logTo(logger Logger, level LogLevel, msg string, fields ...Field) {
+	if logger != nil {
+		logger.Log(level, msg, fields...)
+	}
+}
+
+func (sh *ServiceHub) log(level LogLevel, msg string, fields ...Field) {
+	logTo(sh.logger, level, msg, fields...)
+}
+
+func (r *RegisterHub) log(level LogLevel, msg string, fields ...Field) {
+	logTo(r.logger, level, msg, fields...)
+}
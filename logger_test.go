@@ -0,0 +1,26 @@
+package websocket
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestStdLogger(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	logger := NewStdLogger(&buf, LogWarn)
+	logger.Log(LogInfo, "hidden")
+	logger.Log(LogWarn, "send queue full", F("client_id", "1"), F("size", 256))
+	out := buf.String()
+	if strings.Contains(out, "hidden") {
+		t.Errorf("Log() below level should be dropped, got %q", out)
+	}
+	if !strings.HasSuffix(out, "WARN send queue full client_id=1 size=256\n") {
+		t.Errorf("Log() got %q", out)
+	}
+
+	// 未设置logger时不输出
+	var hub ServiceHub
+	hub.log(LogError, "ignored")
+}
@@ -0,0 +1,352 @@
+package websocket
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	pb "github.com/bin-x/websocket/proto"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// SendToUid的投递结果
+type DeliveryState string
+
+const (
+	// uid在线，已发送给其client
+	DeliveryDelivered DeliveryState = "delivered"
+	// uid离线，已保存到离线消息，绑定uid时自动发送
+	DeliveryStored DeliveryState = "stored"
+	// uid离线且未配置MessageStore，或保存失败
+	DeliveryDropped DeliveryState = "dropped"
+)
+
+// 离线消息存储。SendToUid时uid不在线则保存到调用方所在服务的MessageStore，
+// 之后任意client绑定该uid时，所有服务都会取出各自保存的消息发送给该client。
+type MessageStore interface {
+	// 保存发送给uid的消息
+	Push(uid string, message []byte) error
+	// 取出并删除uid所有未过期的消息
+	Pop(uid string) ([]StoredMessage, error)
+	// uid的投递状态
+	Stats(uid string) (MessageStoreStats, error)
+}
+
+// MessageStore实现该接口时，Pop后发送失败的消息通过Requeue放回，
+// 保留原来的时间，不重新计算过期时间，并从已投递中减去；否则重新Push。
+type MessageRequeuer interface {
+	Requeue(uid string, messages []StoredMessage) error
+}
+
+type StoredMessage struct {
+	Message []byte    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+// uid的投递统计，离线消息全部取出或过期后仍然保留
+type MessageStoreStats struct {
+	// 等待投递
+	Pending int64 `json:"pending"`
+	// 已投递
+	Delivered int64 `json:"delivered"`
+	// 过期丢弃
+	Expired int64 `json:"expired"`
+	// 超过容量丢弃
+	Dropped int64 `json:"dropped"`
+}
+
+type MessageStoreOptions struct {
+	// 消息保存时间，0为不过期
+	TTL time.Duration
+	// 每个uid最多保存的消息数，超出时丢弃最早的消息，0为不限制
+	MaxMessages int
+	// 每个uid最多保存的字节数，超出时丢弃最早的消息，0为不限制
+	MaxBytes int
+}
+
+// 开启离线消息
+func WithMessageStore(store MessageStore) ServiceOption {
+	return func(sh *ServiceHub) {
+		sh.messageStore = store
+	}
+}
+
+// 将本服务保存的uid离线消息发送给刚绑定该uid的client，发送失败的消息重新保存
+func (sh *ServiceHub) flushOfflineMessages(uid, clientId string) {
+	messages, err := sh.messageStore.Pop(uid)
+	if err != nil {
+		sh.log(LogError, "pop offline messages error", F("uid", uid), F("error", err))
+		return
+	}
+	addr, err := nodeOfClientId(clientId)
+	if err != nil {
+		return
+	}
+	for i, message := range messages {
+		response, err := Api.callNode(addr, "SendToClient", context.Background(), &pb.ServiceRequest{ClientId: clientId, Message: message.Message})
+		if err == nil && response.Success {
+			continue
+		}
+		sh.requeueOfflineMessages(uid, messages[i:])
+		return
+	}
+}
+
+func (sh *ServiceHub) requeueOfflineMessages(uid string, messages []StoredMessage) {
+	if requeuer, ok := sh.messageStore.(MessageRequeuer); ok {
+		if err := requeuer.Requeue(uid, messages); err != nil {
+			sh.log(LogError, "requeue offline messages error", F("uid", uid), F("error", err))
+		}
+		return
+	}
+	for _, m := range messages {
+		sh.messageStore.Push(uid, m.Message)
+	}
+}
+
+// 每个uid的离线消息队列
+type uidQueue struct {
+	Messages []StoredMessage   `json:"messages"`
+	Bytes    int               `json:"bytes"`
+	Stats    MessageStoreStats `json:"stats"`
+}
+
+func (q *uidQueue) expire(ttl time.Duration, now time.Time) {
+	if ttl <= 0 {
+		return
+	}
+	i := 0
+	for i < len(q.Messages) && now.Sub(q.Messages[i].Time) > ttl {
+		q.Bytes -= len(q.Messages[i].Message)
+		q.Stats.Expired++
+		i++
+	}
+	q.Messages = q.Messages[i:]
+}
+
+func (q *uidQueue) push(message []byte, options MessageStoreOptions, now time.Time) {
+	q.expire(options.TTL, now)
+	q.Messages = append(q.Messages, StoredMessage{Message: message, Time: now})
+	q.Bytes += len(message)
+	q.trim(options)
+}
+
+// 放回队列头部，保留原来的时间，pop时计入的已投递数减去放回的消息
+func (q *uidQueue) requeue(messages []StoredMessage, options MessageStoreOptions, now time.Time) {
+	requeued := make([]StoredMessage, 0, len(messages)+len(q.Messages))
+	for _, m := range messages {
+		requeued = append(requeued, m)
+		q.Bytes += len(m.Message)
+	}
+	q.Stats.Delivered -= int64(len(messages))
+	if q.Stats.Delivered < 0 {
+		q.Stats.Delivered = 0
+	}
+	q.Messages = append(requeued, q.Messages...)
+	q.expire(options.TTL, now)
+	q.trim(options)
+}
+
+// 超过容量时丢弃最早的消息
+func (q *uidQueue) trim(options MessageStoreOptions) {
+	for len(q.Messages) > 0 &&
+		((options.MaxMessages > 0 && len(q.Messages) > options.MaxMessages) ||
+			(options.MaxBytes > 0 && q.Bytes > options.MaxBytes)) {
+		q.Bytes -= len(q.Messages[0].Message)
+		q.Messages = q.Messages[1:]
+		q.Stats.Dropped++
+	}
+}
+
+func (q *uidQueue) pop(options MessageStoreOptions, now time.Time) []StoredMessage {
+	q.expire(options.TTL, now)
+	messages := q.Messages
+	q.Messages = nil
+	q.Bytes = 0
+	q.Stats.Delivered += int64(len(messages))
+	return messages
+}
+
+func (q *uidQueue) stats(options MessageStoreOptions, now time.Time) MessageStoreStats {
+	q.expire(options.TTL, now)
+	stats := q.Stats
+	stats.Pending = int64(len(q.Messages))
+	return stats
+}
+
+// 内存中的离线消息，服务重启后丢失
+type memoryMessageStore struct {
+	mu      sync.Mutex
+	options MessageStoreOptions
+	queues  map[string]*uidQueue
+	// 与队列分开保存，队列删除后仍然保留
+	stats map[string]MessageStoreStats
+}
+
+func NewMemoryMessageStore(options MessageStoreOptions) MessageStore {
+	return &memoryMessageStore{options: options, queues: make(map[string]*uidQueue), stats: make(map[string]MessageStoreStats)}
+}
+
+func (s *memoryMessageStore) queue(uid string) *uidQueue {
+	q, ok := s.queues[uid]
+	if !ok {
+		q = &uidQueue{Stats: s.stats[uid]}
+		s.queues[uid] = q
+	}
+	return q
+}
+
+// 保存统计，队列为空时删除，避免查询过的uid一直占用内存
+func (s *memoryMessageStore) release(uid string, q *uidQueue) {
+	if q.Stats != (MessageStoreStats{}) {
+		s.stats[uid] = q.Stats
+	}
+	if len(q.Messages) == 0 {
+		delete(s.queues, uid)
+	}
+}
+
+func (s *memoryMessageStore) Push(uid string, message []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q := s.queue(uid)
+	q.push(message, s.options, time.Now())
+	s.release(uid, q)
+	return nil
+}
+
+func (s *memoryMessageStore) Requeue(uid string, messages []StoredMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q := s.queue(uid)
+	q.requeue(messages, s.options, time.Now())
+	s.release(uid, q)
+	return nil
+}
+
+func (s *memoryMessageStore) Pop(uid string) ([]StoredMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q, ok := s.queues[uid]
+	if !ok {
+		return nil, nil
+	}
+	messages := q.pop(s.options, time.Now())
+	s.release(uid, q)
+	return messages, nil
+}
+
+func (s *memoryMessageStore) Stats(uid string) (MessageStoreStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q, ok := s.queues[uid]
+	if !ok {
+		return s.stats[uid], nil
+	}
+	stats := q.stats(s.options, time.Now())
+	s.release(uid, q)
+	return stats, nil
+}
+
+// 保存在文件中的离线消息和统计，每个uid一个文件
+type fileMessageStore struct {
+	mu      sync.Mutex
+	dir     string
+	options MessageStoreOptions
+}
+
+func NewFileMessageStore(dir string, options MessageStoreOptions) (MessageStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &fileMessageStore{dir: dir, options: options}, nil
+}
+
+func (s *fileMessageStore) path(uid string) string {
+	return filepath.Join(s.dir, hex.EncodeToString([]byte(uid))+".json")
+}
+
+func (s *fileMessageStore) load(uid string) (*uidQueue, error) {
+	q := &uidQueue{}
+	data, err := ioutil.ReadFile(s.path(uid))
+	if os.IsNotExist(err) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, q); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// 先写临时文件再重命名，避免写入一半时损坏，没有消息和统计时删除文件
+func (s *fileMessageStore) save(uid string, q *uidQueue) error {
+	if len(q.Messages) == 0 && q.Stats == (MessageStoreStats{}) {
+		if err := os.Remove(s.path(uid)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.Marshal(q)
+	if err != nil {
+		return err
+	}
+	tmp := s.path(uid) + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path(uid))
+}
+
+func (s *fileMessageStore) Push(uid string, message []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q, err := s.load(uid)
+	if err != nil {
+		return err
+	}
+	q.push(message, s.options, time.Now())
+	return s.save(uid, q)
+}
+
+func (s *fileMessageStore) Requeue(uid string, messages []StoredMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q, err := s.load(uid)
+	if err != nil {
+		return err
+	}
+	q.requeue(messages, s.options, time.Now())
+	return s.save(uid, q)
+}
+
+func (s *fileMessageStore) Pop(uid string) ([]StoredMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q, err := s.load(uid)
+	if err != nil {
+		return nil, err
+	}
+	stats := q.Stats
+	messages := q.pop(s.options, time.Now())
+	// 没有消息也没有过期时不写文件
+	if q.Stats == stats {
+		return nil, nil
+	}
+	return messages, s.save(uid, q)
+}
+
+func (s *fileMessageStore) Stats(uid string) (MessageStoreStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q, err := s.load(uid)
+	if err != nil {
+		return MessageStoreStats{}, err
+	}
+	return q.stats(s.options, time.Now()), nil
+}
@@ -0,0 +1,119 @@
+package websocket
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMessageStore(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "message_store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	options := MessageStoreOptions{MaxMessages: 2}
+	fileStore, err := NewFileMessageStore(dir, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stores := map[string]MessageStore{
+		"memory": NewMemoryMessageStore(options),
+		"file":   fileStore,
+	}
+
+	for name, store := range stores {
+		for _, message := range []string{"1", "2", "3"} {
+			if err := store.Push(uid1, []byte(message)); err != nil {
+				t.Errorf("%v Push() error = %v", name, err)
+			}
+		}
+		stats, _ := store.Stats(uid1)
+		if stats.Pending != 2 || stats.Dropped != 1 {
+			t.Errorf("%v Stats() got = %+v, want 2 pending 1 dropped", name, stats)
+		}
+
+		messages, err := store.Pop(uid1)
+		if err != nil || len(messages) != 2 || string(messages[0].Message) != "2" || string(messages[1].Message) != "3" {
+			t.Errorf("%v Pop() got = %v, err = %v", name, messages, err)
+		}
+		// 取空后删除队列，统计仍然保留
+		stats, _ = store.Stats(uid1)
+		if stats != (MessageStoreStats{Delivered: 2, Dropped: 1}) {
+			t.Errorf("%v Stats() after Pop got = %+v, want 2 delivered 1 dropped", name, stats)
+		}
+
+		// 发送失败放回时保留原来的时间和顺序，不计入已投递，超过容量时丢弃最早的消息
+		store.Push(uid1, []byte("4"))
+		if err := store.(MessageRequeuer).Requeue(uid1, messages); err != nil {
+			t.Errorf("%v Requeue() error = %v", name, err)
+		}
+		stats, _ = store.Stats(uid1)
+		if stats != (MessageStoreStats{Pending: 2, Dropped: 2}) {
+			t.Errorf("%v Stats() after Requeue got = %+v, want 2 pending 2 dropped", name, stats)
+		}
+		requeued, _ := store.Pop(uid1)
+		if len(requeued) != 2 || string(requeued[0].Message) != "3" || !requeued[0].Time.Equal(messages[1].Time) || string(requeued[1].Message) != "4" {
+			t.Errorf("%v Pop() after Requeue got = %v", name, requeued)
+		}
+		stats, _ = store.Stats(uid1)
+		if stats != (MessageStoreStats{Delivered: 2, Dropped: 2}) {
+			t.Errorf("%v Stats() after second Pop got = %+v, want 2 delivered 2 dropped", name, stats)
+		}
+
+		if messages, _ := store.Pop(uid2); len(messages) != 0 {
+			t.Errorf("%v Pop() for empty uid got = %v", name, messages)
+		}
+		store.Stats(uid2)
+	}
+
+	// 查询和取出不会创建队列，取空后只保留有统计的uid
+	memory := stores["memory"].(*memoryMessageStore)
+	if len(memory.queues) != 0 || len(memory.stats) != 1 {
+		t.Errorf("memory store got %v queues %v stats, want 0 and 1", len(memory.queues), len(memory.stats))
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 1 {
+		t.Errorf("file store files got = %v, want 1", len(files))
+	}
+	// 统计保存在文件中，重新打开后仍然存在
+	reopened, _ := NewFileMessageStore(dir, options)
+	if stats, _ := reopened.Stats(uid1); stats != (MessageStoreStats{Delivered: 2, Dropped: 2}) {
+		t.Errorf("file Stats() after reopen got = %+v", stats)
+	}
+}
+
+func TestMessageStore_RequeueTTL(t *testing.T) {
+	t.Parallel()
+	options := MessageStoreOptions{TTL: time.Minute}
+	store := NewMemoryMessageStore(options).(*memoryMessageStore)
+	now := time.Now()
+	// 放回的消息按原来的时间过期
+	err := store.Requeue(uid1, []StoredMessage{
+		{Message: []byte("old"), Time: now.Add(-2 * time.Minute)},
+		{Message: []byte("new"), Time: now.Add(-time.Second)},
+	})
+	if err != nil {
+		t.Errorf("Requeue() error = %v", err)
+	}
+	messages, _ := store.Pop(uid1)
+	if len(messages) != 1 || string(messages[0].Message) != "new" {
+		t.Errorf("Pop() after Requeue got = %v, want [new]", messages)
+	}
+}
+
+func TestMessageStore_TTL(t *testing.T) {
+	t.Parallel()
+	options := MessageStoreOptions{TTL: time.Minute}
+	q := &uidQueue{}
+	now := time.Now()
+	q.push([]byte("old"), options, now.Add(-2*time.Minute))
+	q.push([]byte("new"), options, now)
+
+	messages := q.pop(options, now)
+	if len(messages) != 1 || string(messages[0].Message) != "new" || q.Stats.Expired != 1 {
+		t.Errorf("pop() got = %v, expired = %v, want [new] and 1 expired", messages, q.Stats.Expired)
+	}
+}
@@ -0,0 +1,271 @@
+package websocket
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// rpc调用耗时的分桶，单位秒
+var callDurationBuckets = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}
+
+// 在path上提供Prometheus文本格式的监控数据，如 WithMetrics("/metrics")
+func WithMetrics(path string) ServiceOption {
+	return func(sh *ServiceHub) {
+		sh.metrics = &serviceMetrics{path: path, calls: make(map[callKey]*callStats)}
+	}
+}
+
+// 在path上提供注册中心的监控数据
+func WithRegisterMetrics(path string) RegisterOption {
+	return func(r *RegisterHub) {
+		r.metrics = &registerMetrics{path: path, broadcasts: make(map[string]uint64)}
+	}
+}
+
+// service的监控数据，未开启时为nil，所有方法都可以在nil上调用
+type serviceMetrics struct {
+	path string
+
+	received      uint64
+	receivedBytes uint64
+	sent          uint64
+	sentBytes     uint64
+	dropped       uint64
+
+	mu    sync.Mutex
+	calls map[callKey]*callStats
+}
+
+type callKey struct {
+	peer   string
+	method string
+}
+
+type callStats struct {
+	count   uint64
+	errors  uint64
+	sum     float64
+	buckets []uint64
+}
+
+// 由run统计的hub状态
+type hubStats struct {
+	clients    int
+	uids       int
+	groups     int
+	queueDepth int
+}
+
+func (m *serviceMetrics) messageReceived(size int) {
+	if m == nil {
+		return
+	}
+	atomic.AddUint64(&m.received, 1)
+	atomic.AddUint64(&m.receivedBytes, uint64(size))
+}
+
+func (m *serviceMetrics) messageSent(size int) {
+	if m == nil {
+		return
+	}
+	atomic.AddUint64(&m.sent, 1)
+	atomic.AddUint64(&m.sentBytes, uint64(size))
+}
+
+func (m *serviceMetrics) messageDropped() {
+	if m == nil {
+		return
+	}
+	atomic.AddUint64(&m.dropped, 1)
+}
+
+func (m *serviceMetrics) observeCall(peer, method string, duration time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := callKey{peer: peer, method: method}
+	stats, ok := m.calls[key]
+	if !ok {
+		stats = &callStats{buckets: make([]uint64, len(callDurationBuckets))}
+		m.calls[key] = stats
+	}
+	stats.count++
+	if err != nil {
+		stats.errors++
+	}
+	seconds := duration.Seconds()
+	stats.sum += seconds
+	for i, bound := range callDurationBuckets {
+		if seconds <= bound {
+			stats.buckets[i]++
+		}
+	}
+}
+
+func (sh *ServiceHub) hubStats() hubStats {
+	reply := make(chan hubStats)
+	sh.getStats <- reply
+	return <-reply
+}
+
+func (sh *ServiceHub) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	sh.metrics.write(w, sh.hubStats(), sh.OriginRejections())
+}
+
+func (m *serviceMetrics) write(w io.Writer, stats hubStats, originRejections uint64) {
+	p := &metricsWriter{w: w}
+	p.gauge("websocket_clients", "Connected clients on this node.", float64(stats.clients))
+	p.gauge("websocket_uids", "Uids bound on this node.", float64(stats.uids))
+	p.gauge("websocket_groups", "Groups with members on this node.", float64(stats.groups))
+	p.gauge("websocket_send_queue_depth", "Messages waiting in client send queues.", float64(stats.queueDepth))
+	p.counter("websocket_messages_received_total", "Messages received from clients.", float64(atomic.LoadUint64(&m.received)))
+	p.counter("websocket_received_bytes_total", "Bytes received from clients.", float64(atomic.LoadUint64(&m.receivedBytes)))
+	p.counter("websocket_messages_sent_total", "Messages written to clients.", float64(atomic.LoadUint64(&m.sent)))
+	p.counter("websocket_sent_bytes_total", "Bytes written to clients.", float64(atomic.LoadUint64(&m.sentBytes)))
+	p.counter("websocket_send_dropped_total", "Messages dropped because the send queue was full.", float64(atomic.LoadUint64(&m.dropped)))
+	p.counter("websocket_origin_rejected_total", "Upgrades rejected by the origin policy.", float64(originRejections))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	keys := make([]callKey, 0, len(m.calls))
+	for key := range m.calls {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].peer != keys[j].peer {
+			return keys[i].peer < keys[j].peer
+		}
+		return keys[i].method < keys[j].method
+	})
+
+	p.header("websocket_rpc_calls_total", "counter", "Rpc calls by peer and method.")
+	for _, key := range keys {
+		p.sample("websocket_rpc_calls_total", callLabels(key), float64(m.calls[key].count))
+	}
+	p.header("websocket_rpc_errors_total", "counter", "Failed rpc calls by peer and method.")
+	for _, key := range keys {
+		p.sample("websocket_rpc_errors_total", callLabels(key), float64(m.calls[key].errors))
+	}
+	p.header("websocket_rpc_duration_seconds", "histogram", "Rpc call latency by peer and method.")
+	for _, key := range keys {
+		stats := m.calls[key]
+		labels := callLabels(key)
+		for i, bound := range callDurationBuckets {
+			p.sample("websocket_rpc_duration_seconds_bucket", append(labels, "le", formatFloat(bound)), float64(stats.buckets[i]))
+		}
+		p.sample("websocket_rpc_duration_seconds_bucket", append(labels, "le", "+Inf"), float64(stats.count))
+		p.sample("websocket_rpc_duration_seconds_sum", labels, stats.sum)
+		p.sample("websocket_rpc_duration_seconds_count", labels, float64(stats.count))
+	}
+}
+
+func callLabels(key callKey) []string {
+	return []string{"peer", key.peer, "method", key.method}
+}
+
+// 注册中心的监控数据，未开启时为nil
+type registerMetrics struct {
+	path string
+
+	nodes int64
+
+	mu         sync.Mutex
+	broadcasts map[string]uint64
+	dropped    uint64
+}
+
+func (m *registerMetrics) setNodes(n int) {
+	if m == nil {
+		return
+	}
+	atomic.StoreInt64(&m.nodes, int64(n))
+}
+
+func (m *registerMetrics) broadcast(action string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.broadcasts[action]++
+	m.mu.Unlock()
+}
+
+// 发送队列已满被断开的service
+func (m *registerMetrics) nodeDropped() {
+	if m == nil {
+		return
+	}
+	atomic.AddUint64(&m.dropped, 1)
+}
+
+func (r *RegisterHub) serveMetrics(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	r.metrics.write(w, r.OriginRejections())
+}
+
+func (m *registerMetrics) write(w io.Writer, originRejections uint64) {
+	p := &metricsWriter{w: w}
+	p.gauge("websocket_register_nodes", "Services connected to the register.", float64(atomic.LoadInt64(&m.nodes)))
+	p.counter("websocket_register_dropped_nodes_total", "Services dropped because their send queue was full.", float64(atomic.LoadUint64(&m.dropped)))
+	p.counter("websocket_origin_rejected_total", "Upgrades rejected by the origin policy.", float64(originRejections))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	actions := make([]string, 0, len(m.broadcasts))
+	for action := range m.broadcasts {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	p.header("websocket_register_broadcasts_total", "counter", "Broadcasts sent to services by action.")
+	for _, action := range actions {
+		p.sample("websocket_register_broadcasts_total", []string{"action", action}, float64(m.broadcasts[action]))
+	}
+}
+
+// Prometheus文本格式
+type metricsWriter struct {
+	w io.Writer
+}
+
+func (p *metricsWriter) header(name, metricType, help string) {
+	fmt.Fprintf(p.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+func (p *metricsWriter) gauge(name, help string, value float64) {
+	p.header(name, "gauge", help)
+	p.sample(name, nil, value)
+}
+
+func (p *metricsWriter) counter(name, help string, value float64) {
+	p.header(name, "counter", help)
+	p.sample(name, nil, value)
+}
+
+// labels为name、value交替排列
+func (p *metricsWriter) sample(name string, labels []string, value float64) {
+	if len(labels) == 0 {
+		fmt.Fprintf(p.w, "%s %s\n", name, formatFloat(value))
+		return
+	}
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, labels[i]+`="`+labelEscaper.Replace(labels[i+1])+`"`)
+	}
+	fmt.Fprintf(p.w, "%s{%s} %s\n", name, strings.Join(pairs, ","), formatFloat(value))
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,54 @@
+package websocket
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServiceMetrics_write(t *testing.T) {
+	t.Parallel()
+	m := &serviceMetrics{calls: make(map[callKey]*callStats)}
+	m.messageReceived(10)
+	m.messageSent(20)
+	m.messageDropped()
+	m.observeCall("127.0.0.1:8003", "SendToAll", 2*time.Millisecond, nil)
+	m.observeCall("127.0.0.1:8003", "SendToAll", 2*time.Second, errors.New("unavailable"))
+
+	var buf bytes.Buffer
+	m.write(&buf, hubStats{clients: 2, uids: 1, groups: 3, queueDepth: 4}, 5)
+	out := buf.String()
+	for _, want := range []string{
+		"# TYPE websocket_clients gauge\nwebsocket_clients 2\n",
+		"websocket_send_queue_depth 4\n",
+		"websocket_received_bytes_total 10\n",
+		"websocket_sent_bytes_total 20\n",
+		"websocket_send_dropped_total 1\n",
+		"websocket_origin_rejected_total 5\n",
+		`websocket_rpc_calls_total{peer="127.0.0.1:8003",method="SendToAll"} 2`,
+		`websocket_rpc_errors_total{peer="127.0.0.1:8003",method="SendToAll"} 1`,
+		`websocket_rpc_duration_seconds_bucket{peer="127.0.0.1:8003",method="SendToAll",le="0.005"} 1`,
+		`websocket_rpc_duration_seconds_bucket{peer="127.0.0.1:8003",method="SendToAll",le="+Inf"} 2`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("write() missing %q in\n%s", want, out)
+		}
+	}
+
+	// 未开启时可以直接调用
+	var none *serviceMetrics
+	none.messageReceived(1)
+	none.observeCall("", "", 0, nil)
+}
+
+func TestMetricsWriter_escape(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	p := &metricsWriter{w: &buf}
+	p.sample("m", []string{"action", "a\"b\\c\n"}, 1)
+	if want := "m{action=\"a\\\"b\\\\c\\n\"} 1\n"; buf.String() != want {
+		t.Errorf("sample() got = %q, want %q", buf.String(), want)
+	}
+}
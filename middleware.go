@@ -0,0 +1,69 @@
+package websocket
+
+import (
+	pb "github.com/bin-x/websocket/proto"
+)
+
+// 客户端发来的一条消息，在中间件之间传递
+type MessageContext struct {
+	ClientId string
+	// 可以修改，后续的中间件和OnMessage收到修改后的消息
+	Message []byte
+
+	client  *Client
+	session *pb.Client
+	closed  bool
+}
+
+// 最终调用Application.OnMessage
+type MessageHandler func(ctx *MessageContext)
+
+// 客户端消息的中间件，调用next继续处理，不调用时丢弃该消息
+type MessageMiddleware func(ctx *MessageContext, next MessageHandler)
+
+// 添加客户端消息的中间件，在Application.OnMessage之前按添加顺序执行，第一个在最外层。
+// 确认消息、RequestClient的回复和超出限流的消息不经过中间件。
+func WithMessageMiddleware(middlewares ...MessageMiddleware) ServiceOption {
+	return func(sh *ServiceHub) {
+		sh.messageMiddlewares = append(sh.messageMiddlewares, middlewares...)
+	}
+}
+
+func chainMessage(middlewares []MessageMiddleware, handler MessageHandler) MessageHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		middleware, next := middlewares[i], handler
+		handler = func(ctx *MessageContext) {
+			middleware(ctx, next)
+		}
+	}
+	return handler
+}
+
+// 会话信息，第一次调用时获取，会话已结束时返回nil
+func (m *MessageContext) Session() *pb.Client {
+	if m.session == nil {
+		m.session = m.client.snapshot()
+	}
+	return m.session
+}
+
+// 直接回复给该客户端
+func (m *MessageContext) Reply(message []byte) bool {
+	return m.client.push(message)
+}
+
+// 关闭该客户端，之后不再读取该连接的消息
+func (m *MessageContext) Close(code int, reason string) {
+	m.closed = true
+	m.client.requestClose(CloseEvent{Code: code, Reason: reason, Initiator: CloseByServer})
+}
+
+// 经过中间件后交给Application，返回false时停止读取
+func (c *Client) handleMessage(message []byte) bool {
+	ctx := &MessageContext{ClientId: c.id, Message: message, client: c}
+	handler := chainMessage(c.hub.messageMiddlewares, func(ctx *MessageContext) {
+		c.hub.application.OnMessage(ctx.ClientId, ctx.Message)
+	})
+	handler(ctx)
+	return !ctx.closed
+}
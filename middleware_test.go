@@ -0,0 +1,111 @@
+package websocket
+
+import (
+	"bytes"
+	pb "github.com/bin-x/websocket/proto"
+	"testing"
+)
+
+type recordApp struct {
+	testApp
+	messages []string
+}
+
+func (a *recordApp) OnMessage(clientId string, message []byte) {
+	a.messages = append(a.messages, clientId+":"+string(message))
+}
+
+func TestClient_handleMessage(t *testing.T) {
+	t.Parallel()
+	upper := func(ctx *MessageContext, next MessageHandler) {
+		ctx.Message = bytes.ToUpper(ctx.Message)
+		next(ctx)
+	}
+	auth := func(ctx *MessageContext, next MessageHandler) {
+		if ctx.Session().Uid == "" {
+			ctx.Reply([]byte("unauthorized"))
+			return
+		}
+		next(ctx)
+	}
+	kick := func(ctx *MessageContext, next MessageHandler) {
+		if string(ctx.Message) == "BYE" {
+			ctx.Close(4100, "bye")
+			return
+		}
+		next(ctx)
+	}
+	tests := []struct {
+		name    string
+		uid     string
+		message string
+		want    []string
+		reply   string
+		closed  bool
+	}{
+		{"transform", uid1, "hello", []string{"1:HELLO"}, "", false},
+		{"reply and drop", "", "hello", nil, "unauthorized", false},
+		{"close", uid1, "bye", nil, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := CreateHub()
+			app := &recordApp{}
+			hub.application = app
+			hub.messageMiddlewares = []MessageMiddleware{upper, auth, kick}
+			// 不使用hub中的client，避免其run读取done
+			client := &Client{hub: hub, id: "1", uid: tt.uid, send: make(chan []byte, 1), done: make(chan CloseEvent),
+				getSession: make(chan chan *pb.Client), closed: make(chan struct{})}
+			defer close(client.closed)
+			// 代替run生成会话信息
+			go func() {
+				for {
+					select {
+					case reply := <-client.getSession:
+						reply <- client.session()
+					case <-client.closed:
+						return
+					}
+				}
+			}()
+			var event CloseEvent
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				if tt.closed {
+					event = <-client.done
+				}
+			}()
+			if got := client.handleMessage([]byte(tt.message)); got == tt.closed {
+				t.Errorf("handleMessage() = %v", got)
+			}
+			<-done
+			if len(app.messages) != len(tt.want) || (len(tt.want) > 0 && app.messages[0] != tt.want[0]) {
+				t.Errorf("OnMessage got = %v, want %v", app.messages, tt.want)
+			}
+			if tt.reply != "" && string(<-client.send) != tt.reply {
+				t.Errorf("reply not sent")
+			}
+			if tt.closed && (event.Code != 4100 || event.Reason != "bye") {
+				t.Errorf("close event got = %+v", event)
+			}
+		})
+	}
+}
+
+func TestMessageContext_Session(t *testing.T) {
+	t.Parallel()
+	hub := CreateHub()
+	// 通过client的run获取，与run中修改分组不冲突
+	ctx := &MessageContext{ClientId: "1", client: hub.clients["1"]}
+	hub.clients["1"].joinGroup <- "room"
+	if session := ctx.Session(); session == nil || session.Uid != uid1 || len(session.Group) != 2 {
+		t.Errorf("Session() got = %v", session)
+	}
+
+	closed := &Client{hub: hub, id: "3", getSession: make(chan chan *pb.Client), closed: make(chan struct{})}
+	close(closed.closed)
+	if session := (&MessageContext{client: closed}).Session(); session != nil {
+		t.Errorf("Session() after closed got = %v, want nil", session)
+	}
+}
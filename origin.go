@@ -0,0 +1,100 @@
+package websocket
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+)
+
+// 允许建立websocket连接的来源。
+// 没有Origin头（非浏览器客户端）或Origin与请求的Host相同时总是允许，
+// 其他情况下Origin匹配Origins中的任意一项或Check返回true时允许。
+type OriginPolicy struct {
+	// 如 "https://example.com"，"https://*.example.com" 匹配所有子域名（不包括example.com本身），"*" 允许所有来源
+	Origins []string
+	// 自定义检查，可以为nil
+	Check func(r *http.Request) bool
+}
+
+// 设置允许的来源，未设置时只允许与请求的Host相同的来源
+func WithOriginPolicy(policy OriginPolicy) ServiceOption {
+	return func(sh *ServiceHub) {
+		sh.origin = newOriginChecker(policy)
+	}
+}
+
+// 设置注册中心允许的来源，service连接时没有Origin头，不受影响
+func WithRegisterOriginPolicy(policy OriginPolicy) RegisterOption {
+	return func(r *RegisterHub) {
+		r.origin = newOriginChecker(policy)
+	}
+}
+
+type originChecker struct {
+	policy OriginPolicy
+	// 拒绝的次数
+	rejected uint64
+	logger   Logger
+}
+
+func newOriginChecker(policy OriginPolicy) *originChecker {
+	return &originChecker{policy: policy}
+}
+
+func (o *originChecker) check(r *http.Request) bool {
+	if o.allow(r) {
+		return true
+	}
+	atomic.AddUint64(&o.rejected, 1)
+	logTo(o.logger, LogWarn, "reject websocket origin", F("origin", r.Header.Get("Origin")), F("remote_addr", r.RemoteAddr))
+	return false
+}
+
+func (o *originChecker) allow(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	for _, pattern := range o.policy.Origins {
+		if matchOrigin(pattern, u) {
+			return true
+		}
+	}
+	return o.policy.Check != nil && o.policy.Check(r)
+}
+
+func (o *originChecker) rejections() uint64 {
+	return atomic.LoadUint64(&o.rejected)
+}
+
+func matchOrigin(pattern string, origin *url.URL) bool {
+	if pattern == "*" {
+		return true
+	}
+	p, err := url.Parse(pattern)
+	if err != nil || !strings.EqualFold(p.Scheme, origin.Scheme) {
+		return false
+	}
+	if strings.HasPrefix(p.Host, "*.") {
+		return strings.HasSuffix(strings.ToLower(origin.Host), strings.ToLower(p.Host[1:]))
+	}
+	return strings.EqualFold(p.Host, origin.Host)
+}
+
+// 因来源不被允许而拒绝的连接数
+func (sh *ServiceHub) OriginRejections() uint64 {
+	return sh.origin.rejections()
+}
+
+// 因来源不被允许而拒绝的连接数
+func (r *RegisterHub) OriginRejections() uint64 {
+	return r.origin.rejections()
+}
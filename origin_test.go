@@ -0,0 +1,45 @@
+package websocket
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestOriginChecker_check(t *testing.T) {
+	t.Parallel()
+	o := newOriginChecker(OriginPolicy{
+		Origins: []string{"https://example.com", "https://*.example.org"},
+		Check: func(r *http.Request) bool {
+			return r.Header.Get("Origin") == "https://custom.com"
+		},
+	})
+	tests := []struct {
+		name   string
+		origin string
+		want   bool
+	}{
+		{"no origin", "", true},
+		{"same host", "https://ws.test.com", true},
+		{"exact", "https://example.com", true},
+		{"exact wrong scheme", "http://example.com", false},
+		{"wildcard subdomain", "https://a.b.example.org", true},
+		{"wildcard parent", "https://example.org", false},
+		{"wildcard suffix trick", "https://evilexample.org", false},
+		{"custom check", "https://custom.com", true},
+		{"other", "https://evil.com", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := http.NewRequest("GET", "https://ws.test.com/ws", nil)
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			if got := o.check(r); got != tt.want {
+				t.Errorf("check() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if o.rejections() != 4 {
+		t.Errorf("rejections() got = %v, want 4", o.rejections())
+	}
+}
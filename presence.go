@@ -0,0 +1,109 @@
+package websocket
+
+import (
+	"encoding/json"
+	"sync/atomic"
+)
+
+const (
+	PresenceOnline  = "online"
+	PresenceOffline = "offline"
+
+	// 订阅上下线事件的客户端会加入以该前缀开头的分组
+	presenceGroupPrefix = "__presence__"
+)
+
+// uid在整个集群中的上下线事件。
+// 第一个client在任意节点绑定该uid时为online，
+// 最后一个client在任意节点关闭、解绑或所在节点宕机时为offline。
+type PresenceEvent struct {
+	Uid    string `json:"uid"`
+	Status string `json:"status"`
+}
+
+type groupMessage struct {
+	group   string
+	message []byte
+}
+
+// 推送给订阅客户端的消息格式：{"type":"presence","data":{"uid":"xxx","status":"online"}}
+type presenceMessage struct {
+	Type string        `json:"type"`
+	Data PresenceEvent `json:"data"`
+}
+
+// PresenceChannel() 返回订阅某个uid上下线事件的分组名，uid为空时订阅所有uid。
+// 客户端通过 Api.JoinGroup(clientId, PresenceChannel(uid)) 订阅。
+func PresenceChannel(uid string) string {
+	if uid == "" {
+		return presenceGroupPrefix
+	}
+	return presenceGroupPrefix + ":" + uid
+}
+
+// 注册上下线事件回调，每个节点都会收到集群中所有的上下线事件。
+// 需要在Start之前调用。
+func (sh *ServiceHub) OnPresence(handler func(event PresenceEvent)) {
+	sh.presenceHandlers = append(sh.presenceHandlers, handler)
+}
+
+// 本地第一个client绑定了uid
+func (sh *ServiceHub) localUidOnline(uid string) {
+	if uid == "" {
+		return
+	}
+	sh.sendToRegister(&RegisterMessage{Action: registerActionUidOnline, Uids: []string{uid}})
+}
+
+// 本地最后一个client解绑了uid
+func (sh *ServiceHub) localUidOffline(uid string) {
+	if uid == "" {
+		return
+	}
+	sh.sendToRegister(&RegisterMessage{Action: registerActionUidOffline, Uids: []string{uid}})
+}
+
+// 与register断开时直接丢弃，重连后会同步全部uid。
+// 连接时队列已满也会丢弃，之后重新同步全部uid。
+func (sh *ServiceHub) sendToRegister(message *RegisterMessage) {
+	select {
+	case sh.registerSend <- message:
+	default:
+		if atomic.LoadInt32(&sh.registerConnected) == 1 {
+			sh.log(LogWarn, "register send queue full, resync uids", F("action", message.Action))
+			select {
+			case sh.registerResync <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// 获取本地所有已绑定的uid，由run负责读取，避免并发访问
+func (sh *ServiceHub) localUids() []string {
+	reply := make(chan []string)
+	sh.getUids <- reply
+	return <-reply
+}
+
+// 分发register广播的上下线事件
+func (sh *ServiceHub) dispatchPresence() {
+	for event := range sh.presenceEvents {
+		for _, handler := range sh.presenceHandlers {
+			handler(event)
+		}
+
+		message, err := json.Marshal(presenceMessage{Type: "presence", Data: event})
+		if err != nil {
+			sh.log(LogError, "marshal presence message error", F("error", err))
+			continue
+		}
+		sh.sendToLocalGroup(PresenceChannel(""), message)
+		sh.sendToLocalGroup(PresenceChannel(event.Uid), message)
+	}
+}
+
+// 由run发送给分组的成员，避免并发访问groups
+func (sh *ServiceHub) sendToLocalGroup(group string, message []byte) {
+	sh.groupMessages <- &groupMessage{group: group, message: message}
+}
@@ -0,0 +1,154 @@
+package websocket
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestRegisterClient(hub *RegisterHub, rpcAddr string, queue int) *RegisterClient {
+	return &RegisterClient{hub: hub, rpcAddr: rpcAddr, send: make(chan []byte, queue), uids: make(map[string]bool)}
+}
+
+// 读取client收到的所有上下线广播
+func presenceBroadcasts(client *RegisterClient) []string {
+	var events []string
+	for len(client.send) > 0 {
+		var message RegisterMessage
+		json.Unmarshal(<-client.send, &message)
+		if message.Action != registerActionBroadcastOnline && message.Action != registerActionBroadcastOffline {
+			continue
+		}
+		sort.Strings(message.Uids)
+		for _, uid := range message.Uids {
+			events = append(events, message.Action+":"+uid)
+		}
+	}
+	return events
+}
+
+func TestRegisterHub_presence(t *testing.T) {
+	t.Parallel()
+	r := NewRegisterHub(WithRegisterLogger(nil))
+	a := newTestRegisterClient(r, "127.0.0.1:1", 64)
+	b := newTestRegisterClient(r, "127.0.0.1:2", 64)
+	r.clients[a], r.clients[b] = true, true
+
+	tests := []struct {
+		name   string
+		client *RegisterClient
+		action string
+		uids   []string
+		want   []string
+	}{
+		{"first online", a, registerActionUidOnline, []string{"u1", "u2"}, []string{"broadcast_online:u1", "broadcast_online:u2"}},
+		{"already online on another service", b, registerActionUidOnline, []string{"u1"}, nil},
+		{"repeated online", a, registerActionUidOnline, []string{"u1"}, nil},
+		{"not the last service", a, registerActionUidOffline, []string{"u1"}, nil},
+		{"last service", b, registerActionUidOffline, []string{"u1"}, []string{"broadcast_offline:u1"}},
+		{"not online", b, registerActionUidOffline, []string{"u2"}, nil},
+		{"sync", a, registerActionSyncUids, []string{"u3"}, []string{"broadcast_offline:u2", "broadcast_online:u3"}},
+	}
+	for _, tt := range tests {
+		r.updatePresence(&presenceUpdate{client: tt.client, action: tt.action, uids: tt.uids})
+		got := presenceBroadcasts(b)
+		presenceBroadcasts(a)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: broadcasts got = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRegisterHub_removeClient(t *testing.T) {
+	t.Parallel()
+	r := NewRegisterHub(WithRegisterLogger(nil))
+	a := newTestRegisterClient(r, "127.0.0.1:1", 64)
+	b := newTestRegisterClient(r, "127.0.0.1:2", 64)
+	r.clients[a], r.clients[b] = true, true
+	r.addUids(a, []string{"u1", "u2"})
+	r.addUids(b, []string{"u2"})
+	presenceBroadcasts(b)
+
+	r.removeClient(a)
+	if got := presenceBroadcasts(b); !reflect.DeepEqual(got, []string{"broadcast_offline:u1"}) {
+		t.Errorf("broadcasts got = %v", got)
+	}
+	if _, ok := r.uidServices["u1"]; ok || len(r.uidServices["u2"]) != 1 {
+		t.Errorf("uidServices got = %v", r.uidServices)
+	}
+}
+
+func TestRegisterHub_broadcast_dropSlowService(t *testing.T) {
+	t.Parallel()
+	r := NewRegisterHub(WithRegisterLogger(nil))
+	slow := newTestRegisterClient(r, "127.0.0.1:1", 0)
+	b := newTestRegisterClient(r, "127.0.0.1:2", 64)
+	r.clients[slow], r.clients[b] = true, true
+	slow.uids["u1"] = true
+	r.uidServices["u1"] = map[*RegisterClient]bool{slow: true}
+
+	r.broadcast(&RegisterMessage{Action: registerActionBroadcastAddr})
+	if r.clients[slow] {
+		t.Errorf("slow service not removed")
+	}
+	if _, ok := r.uidServices["u1"]; ok {
+		t.Errorf("uids of the slow service not removed")
+	}
+	if got := presenceBroadcasts(b); !reflect.DeepEqual(got, []string{"broadcast_offline:u1"}) {
+		t.Errorf("broadcasts got = %v", got)
+	}
+}
+
+func TestServiceHub_sendToRegister(t *testing.T) {
+	t.Parallel()
+	sh := &ServiceHub{registerSend: make(chan *RegisterMessage, 1), registerResync: make(chan struct{}, 1)}
+	message := &RegisterMessage{Action: registerActionUidOnline, Uids: []string{"u1"}}
+
+	// 未连接时丢弃，重连后会同步
+	sh.sendToRegister(message)
+	sh.sendToRegister(message)
+	if len(sh.registerResync) != 0 {
+		t.Errorf("resync scheduled while disconnected")
+	}
+
+	atomic.StoreInt32(&sh.registerConnected, 1)
+	sh.sendToRegister(message)
+	sh.sendToRegister(message)
+	if len(sh.registerResync) != 1 {
+		t.Errorf("resync not scheduled after a drop")
+	}
+}
+
+func TestServiceHub_dispatchPresence(t *testing.T) {
+	t.Parallel()
+	hub := CreateHub()
+	hub.presenceEvents = make(chan PresenceEvent)
+	var handled []PresenceEvent
+	hub.presenceHandlers = []func(event PresenceEvent){func(event PresenceEvent) { handled = append(handled, event) }}
+	client1, client2 := hub.clients["1"], hub.clients["2"]
+	hub.joinGroup <- map[*Client]string{client1: PresenceChannel("")}
+	hub.joinGroup <- map[*Client]string{client2: PresenceChannel(uid1)}
+	go hub.dispatchPresence()
+
+	// 分组成员由run发送，与run中修改分组不冲突
+	event := PresenceEvent{Uid: uid1, Status: PresenceOnline}
+	hub.presenceEvents <- event
+	want := `{"type":"presence","data":{"uid":"` + uid1 + `","status":"online"}}`
+	for _, client := range []*Client{client1, client2} {
+		select {
+		case message := <-client.send:
+			if string(message) != want {
+				t.Errorf("client %v got = %s, want %s", client.id, message, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("client %v didn't receive presence message", client.id)
+		}
+	}
+	close(hub.presenceEvents)
+	if len(handled) != 1 || handled[0] != event {
+		t.Errorf("presence handlers got = %v", handled)
+	}
+}
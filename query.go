@@ -0,0 +1,236 @@
+package websocket
+
+import (
+	pb "github.com/bin-x/websocket/proto"
+	"strings"
+	"sync"
+)
+
+const (
+	QueryFieldInfo  = "info"
+	QueryFieldUid   = "uid"
+	QueryFieldGroup = "group"
+
+	QueryOpEq     = "eq"
+	QueryOpPrefix = "prefix"
+	QueryOpIn     = "in"
+)
+
+// 按info、uid、分组查询client，所有条件都满足才匹配。
+// 例如查询房间x中版本为4.x的iOS客户端：
+//	NewQuery().InfoEq("room", "x").InfoEq("platform", "ios").InfoPrefix("version", "4.")
+type Query struct {
+	predicates []*pb.Predicate
+}
+
+func NewQuery() *Query {
+	return &Query{}
+}
+
+func (q *Query) where(field, key, op string, values ...string) *Query {
+	q.predicates = append(q.predicates, &pb.Predicate{Field: field, Key: key, Op: op, Values: values})
+	return q
+}
+
+// info中key的值等于value
+func (q *Query) InfoEq(key, value string) *Query {
+	return q.where(QueryFieldInfo, key, QueryOpEq, value)
+}
+
+// info中key的值以prefix开头
+func (q *Query) InfoPrefix(key, prefix string) *Query {
+	return q.where(QueryFieldInfo, key, QueryOpPrefix, prefix)
+}
+
+// info中key的值为values中的一个
+func (q *Query) InfoIn(key string, values ...string) *Query {
+	return q.where(QueryFieldInfo, key, QueryOpIn, values...)
+}
+
+func (q *Query) UidEq(uid string) *Query {
+	return q.where(QueryFieldUid, "", QueryOpEq, uid)
+}
+
+func (q *Query) UidPrefix(prefix string) *Query {
+	return q.where(QueryFieldUid, "", QueryOpPrefix, prefix)
+}
+
+func (q *Query) UidIn(uids ...string) *Query {
+	return q.where(QueryFieldUid, "", QueryOpIn, uids...)
+}
+
+// 在某个分组中
+func (q *Query) InGroup(group string) *Query {
+	return q.where(QueryFieldGroup, "", QueryOpEq, group)
+}
+
+// 在任意一个以prefix开头的分组中
+func (q *Query) InGroupPrefix(prefix string) *Query {
+	return q.where(QueryFieldGroup, "", QueryOpPrefix, prefix)
+}
+
+// 在groups中的任意一个分组中
+func (q *Query) InAnyGroup(groups ...string) *Query {
+	return q.where(QueryFieldGroup, "", QueryOpIn, groups...)
+}
+
+func matchValue(op string, value string, values []string) bool {
+	switch op {
+	case QueryOpEq:
+		return len(values) > 0 && value == values[0]
+	case QueryOpPrefix:
+		return len(values) > 0 && strings.HasPrefix(value, values[0])
+	case QueryOpIn:
+		for _, v := range values {
+			if value == v {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *Client) match(predicate *pb.Predicate) bool {
+	switch predicate.Field {
+	case QueryFieldInfo:
+		value, ok := c.info[predicate.Key]
+		return ok && matchValue(predicate.Op, value, predicate.Values)
+	case QueryFieldUid:
+		return c.uid != "" && matchValue(predicate.Op, c.uid, predicate.Values)
+	case QueryFieldGroup:
+		for group := range c.groups {
+			if matchValue(predicate.Op, group, predicate.Values) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// 根据查询条件缩小需要检查的client范围，优先使用info索引
+func (sh *ServiceHub) queryCandidates(predicates []*pb.Predicate) map[*Client]bool {
+	var candidates map[*Client]bool
+	found := false
+	for _, predicate := range predicates {
+		if predicate.Op != QueryOpEq && predicate.Op != QueryOpIn {
+			continue
+		}
+		var clients map[*Client]bool
+		switch predicate.Field {
+		case QueryFieldInfo:
+			var ok bool
+			if clients, ok = sh.infoIndex.lookup(predicate.Key, predicate.Values); !ok {
+				continue
+			}
+		case QueryFieldUid:
+			clients = unionClients(sh.uidClients, predicate.Values)
+		case QueryFieldGroup:
+			clients = unionClients(sh.groups, predicate.Values)
+		default:
+			continue
+		}
+		if !found || len(clients) < len(candidates) {
+			candidates = clients
+			found = true
+		}
+	}
+	if found {
+		return candidates
+	}
+
+	candidates = make(map[*Client]bool, len(sh.clients))
+	for _, client := range sh.clients {
+		candidates[client] = true
+	}
+	return candidates
+}
+
+func unionClients(m map[string]map[*Client]bool, keys []string) map[*Client]bool {
+	clients := make(map[*Client]bool)
+	for _, key := range keys {
+		for client := range m[key] {
+			clients[client] = true
+		}
+	}
+	return clients
+}
+
+// info的本地二级索引：key -> value -> clients
+type infoIndex struct {
+	mu      sync.RWMutex
+	keys    map[string]bool
+	values  map[string]map[string]map[*Client]bool
+	clients map[*Client]map[string]string
+}
+
+func newInfoIndex() *infoIndex {
+	return &infoIndex{
+		keys:    make(map[string]bool),
+		values:  make(map[string]map[string]map[*Client]bool),
+		clients: make(map[*Client]map[string]string),
+	}
+}
+
+// client的info变化后更新索引
+func (idx *infoIndex) update(client *Client, info map[string]string) {
+	if idx == nil {
+		return
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.removeLocked(client)
+
+	indexed := make(map[string]string)
+	for key := range idx.keys {
+		value, ok := info[key]
+		if !ok {
+			continue
+		}
+		indexed[key] = value
+		if _, ok := idx.values[key]; !ok {
+			idx.values[key] = make(map[string]map[*Client]bool)
+		}
+		if _, ok := idx.values[key][value]; !ok {
+			idx.values[key][value] = make(map[*Client]bool)
+		}
+		idx.values[key][value][client] = true
+	}
+	if len(indexed) > 0 {
+		idx.clients[client] = indexed
+	}
+}
+
+func (idx *infoIndex) remove(client *Client) {
+	if idx == nil {
+		return
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.removeLocked(client)
+}
+
+func (idx *infoIndex) removeLocked(client *Client) {
+	for key, value := range idx.clients[client] {
+		delete(idx.values[key][value], client)
+		if len(idx.values[key][value]) == 0 {
+			delete(idx.values[key], value)
+		}
+	}
+	delete(idx.clients, client)
+}
+
+// 查询info中key的值为values之一的client，key没有索引时返回false
+func (idx *infoIndex) lookup(key string, values []string) (map[*Client]bool, bool) {
+	if idx == nil || !idx.keys[key] {
+		return nil, false
+	}
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	clients := make(map[*Client]bool)
+	for _, value := range values {
+		for client := range idx.values[key][value] {
+			clients[client] = true
+		}
+	}
+	return clients, true
+}
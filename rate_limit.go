@@ -0,0 +1,217 @@
+package websocket
+
+import (
+	"github.com/gorilla/websocket"
+	"net"
+	"sync"
+	"time"
+)
+
+// 限流的维度
+type RateLimitKey string
+
+const (
+	RateLimitByClient RateLimitKey = "client"
+	// 未绑定uid的client不限流
+	RateLimitByUid RateLimitKey = "uid"
+	RateLimitByIp  RateLimitKey = "ip"
+)
+
+// 超出限制时的处理方式
+type RateLimitAction string
+
+const (
+	// 丢弃消息
+	RateLimitDrop RateLimitAction = "drop"
+	// 等待令牌足够后再处理，同时暂停读取该连接
+	RateLimitDelay RateLimitAction = "delay"
+	// 丢弃消息并发送 {"type":"rate_limited","data":{...}} 给客户端
+	RateLimitWarn RateLimitAction = "warn"
+	// 以1008关闭连接
+	RateLimitClose RateLimitAction = "close"
+)
+
+const envelopeTypeRateLimited = "rate_limited"
+
+// 令牌桶限制，Messages、Bytes为每秒的数量，0为不限制，Burst未设置时与每秒的数量相同
+type RateLimit struct {
+	Messages     float64
+	MessageBurst int
+	Bytes        float64
+	ByteBurst    int
+}
+
+// 传给Application的超限信息
+type RateLimitViolation struct {
+	Key RateLimitKey `json:"key"`
+	// client id、uid或ip
+	Value string `json:"value"`
+	// 超出的是消息数(messages)还是字节数(bytes)
+	Limit      string          `json:"limit"`
+	Action     RateLimitAction `json:"action"`
+	RetryAfter time.Duration   `json:"retry_after"`
+}
+
+// Application实现该接口时，超出限流会调用OnRateLimit
+type RateLimitHandler interface {
+	OnRateLimit(clientId string, violation RateLimitViolation)
+}
+
+// 限制客户端发送消息的速度，可以多次调用设置不同维度的限制，按设置顺序检查
+func WithRateLimit(key RateLimitKey, limit RateLimit, action RateLimitAction) ServiceOption {
+	return func(sh *ServiceHub) {
+		sh.rateLimiters = append(sh.rateLimiters, newRateLimiter(key, limit, action))
+	}
+}
+
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int, now time.Time) *tokenBucket {
+	b := float64(burst)
+	if b <= 0 {
+		b = rate
+	}
+	return &tokenBucket{rate: rate, burst: b, tokens: b, last: now}
+}
+
+// 取出n个令牌，不足时返回需要等待的时间。reserve为true时即使不足也预先扣除
+func (b *tokenBucket) take(n float64, now time.Time, reserve bool) time.Duration {
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	if b.tokens >= n {
+		b.tokens -= n
+		return 0
+	}
+	wait := time.Duration((n - b.tokens) / b.rate * float64(time.Second))
+	if reserve {
+		b.tokens -= n
+	}
+	return wait
+}
+
+type rateBuckets struct {
+	messages *tokenBucket
+	bytes    *tokenBucket
+	used     time.Time
+}
+
+type rateLimiter struct {
+	mu        sync.Mutex
+	key       RateLimitKey
+	limit     RateLimit
+	action    RateLimitAction
+	buckets   map[string]*rateBuckets
+	lastSweep time.Time
+}
+
+func newRateLimiter(key RateLimitKey, limit RateLimit, action RateLimitAction) *rateLimiter {
+	return &rateLimiter{key: key, limit: limit, action: action, buckets: make(map[string]*rateBuckets), lastSweep: time.Now()}
+}
+
+// 检查value的一条消息，超出时返回超出的限制和需要等待的时间
+func (l *rateLimiter) check(value string, size int, now time.Time) (string, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+	b, ok := l.buckets[value]
+	if !ok {
+		b = &rateBuckets{}
+		if l.limit.Messages > 0 {
+			b.messages = newTokenBucket(l.limit.Messages, l.limit.MessageBurst, now)
+		}
+		if l.limit.Bytes > 0 {
+			b.bytes = newTokenBucket(l.limit.Bytes, l.limit.ByteBurst, now)
+		}
+		l.buckets[value] = b
+	}
+	b.used = now
+
+	reserve := l.action == RateLimitDelay
+	var limit string
+	var wait time.Duration
+	if b.messages != nil {
+		if w := b.messages.take(1, now, reserve); w > 0 {
+			limit, wait = "messages", w
+		}
+	}
+	if b.bytes != nil && (limit == "" || reserve) {
+		if w := b.bytes.take(float64(size), now, reserve); w > wait {
+			limit, wait = "bytes", w
+		}
+	}
+	return limit, wait
+}
+
+// 删除一段时间未使用的桶，这时令牌已经补满
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for value, b := range l.buckets {
+		if now.Sub(b.used) > time.Minute {
+			delete(l.buckets, value)
+		}
+	}
+}
+
+// 检查客户端发来的消息，返回false时丢弃该消息，closeEvent不为nil时需要以此关闭连接
+func (c *Client) checkRateLimit(conn *websocket.Conn, message []byte) (allow bool, closeEvent *CloseEvent) {
+	for _, limiter := range c.hub.rateLimiters {
+		value := c.rateLimitValue(limiter.key, conn)
+		if value == "" {
+			continue
+		}
+		limit, wait := limiter.check(value, len(message), time.Now())
+		if limit == "" {
+			continue
+		}
+		violation := RateLimitViolation{Key: limiter.key, Value: value, Limit: limit, Action: limiter.action, RetryAfter: wait}
+		if handler, ok := c.hub.application.(RateLimitHandler); ok {
+			if !c.protect(func() { handler.OnRateLimit(c.id, violation) }) {
+				return false, &closeInternalError
+			}
+		}
+		switch limiter.action {
+		case RateLimitDelay:
+			time.Sleep(wait)
+		case RateLimitWarn:
+			warning, _ := MarshalEnvelope(envelopeTypeRateLimited, "", map[string]interface{}{
+				"key":            violation.Key,
+				"limit":          violation.Limit,
+				"retry_after_ms": wait.Milliseconds(),
+			})
+			c.push(warning)
+			return false, nil
+		case RateLimitClose:
+			return false, &CloseEvent{Code: websocket.ClosePolicyViolation, Reason: "rate limit exceeded", Initiator: CloseByServer}
+		default:
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (c *Client) rateLimitValue(key RateLimitKey, conn *websocket.Conn) string {
+	switch key {
+	case RateLimitByClient:
+		return c.id
+	case RateLimitByUid:
+		return c.loadUid()
+	case RateLimitByIp:
+		host, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+		if err != nil {
+			return conn.RemoteAddr().String()
+		}
+		return host
+	}
+	return ""
+}
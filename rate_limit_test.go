@@ -0,0 +1,122 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_check(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	tests := []struct {
+		name      string
+		limit     RateLimit
+		action    RateLimitAction
+		sizes     []int
+		wantLimit string
+	}{
+		{"under message limit", RateLimit{Messages: 2}, RateLimitDrop, []int{1, 1}, ""},
+		{"over message limit", RateLimit{Messages: 2}, RateLimitDrop, []int{1, 1, 1}, "messages"},
+		{"burst", RateLimit{Messages: 1, MessageBurst: 3}, RateLimitDrop, []int{1, 1, 1}, ""},
+		{"over byte limit", RateLimit{Bytes: 100}, RateLimitDrop, []int{60, 60}, "bytes"},
+		{"delay reserves tokens", RateLimit{Messages: 1}, RateLimitDelay, []int{1, 1}, "messages"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newRateLimiter(RateLimitByClient, tt.limit, tt.action)
+			var limit string
+			var wait time.Duration
+			for _, size := range tt.sizes {
+				limit, wait = l.check("1", size, now)
+			}
+			if limit != tt.wantLimit || (limit != "" && wait <= 0) {
+				t.Errorf("check() got = %v, %v, want %v", limit, wait, tt.wantLimit)
+			}
+			// 另一个key不受影响
+			if limit, _ := l.check("2", tt.sizes[0], now); limit != "" {
+				t.Errorf("check() other key got = %v", limit)
+			}
+		})
+	}
+}
+
+func TestTokenBucket_refill(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	b := newTokenBucket(10, 1, now)
+	if wait := b.take(1, now, false); wait != 0 {
+		t.Errorf("take() got wait = %v, want 0", wait)
+	}
+	if wait := b.take(1, now, false); wait != 100*time.Millisecond {
+		t.Errorf("take() got wait = %v, want 100ms", wait)
+	}
+	if wait := b.take(1, now.Add(100*time.Millisecond), false); wait != 0 {
+		t.Errorf("take() after refill got wait = %v, want 0", wait)
+	}
+}
+
+func TestClient_rateLimitValue_uid(t *testing.T) {
+	t.Parallel()
+	hub := CreateHub()
+	client := hub.clients["2"]
+
+	// hub的run修改uid时，read读取的是副本
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			client.rateLimitValue(RateLimitByUid, nil)
+		}
+	}()
+	hub.bindUid <- map[*Client]string{client: "new"}
+	<-done
+	waitHub(hub)
+	if uid := client.rateLimitValue(RateLimitByUid, nil); uid != "new" {
+		t.Errorf("rateLimitValue() after BindUid got = %v, want new", uid)
+	}
+	hub.unbindUid <- client
+	waitHub(hub)
+	if uid := client.rateLimitValue(RateLimitByUid, nil); uid != "" {
+		t.Errorf("rateLimitValue() after UnbindUid got = %v, want empty", uid)
+	}
+}
+
+type panicRateLimitApp struct {
+	errorApp
+}
+
+func (a *panicRateLimitApp) OnRateLimit(clientId string, violation RateLimitViolation) {
+	panic("boom")
+}
+
+func TestClient_checkRateLimit_panic(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		policy    PanicPolicy
+		wantClose *CloseEvent
+	}{
+		{"close", PanicCloseConnection, &closeInternalError},
+		{"keep", PanicKeepConnection, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := CreateHub()
+			app := &panicRateLimitApp{}
+			hub.application = app
+			hub.panicPolicy = tt.policy
+			hub.rateLimiters = []*rateLimiter{newRateLimiter(RateLimitByClient, RateLimit{Messages: 1}, RateLimitDrop)}
+			client := hub.clients["1"]
+
+			client.checkRateLimit(nil, []byte("1"))
+			// OnRateLimit的panic按PanicPolicy处理，并调用OnError
+			allow, closeEvent := client.checkRateLimit(nil, []byte("2"))
+			if allow || (closeEvent == nil) != (tt.wantClose == nil) || (closeEvent != nil && *closeEvent != *tt.wantClose) {
+				t.Errorf("checkRateLimit() got = %v, %v, want false, %v", allow, closeEvent, tt.wantClose)
+			}
+			if len(app.errors) != 1 || app.errors[0].Kind != ErrorKindPanic {
+				t.Errorf("OnError got = %+v", app.errors)
+			}
+		})
+	}
+}
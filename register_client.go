@@ -5,19 +5,12 @@
 package websocket
 
 import (
-	"log"
-
 	"net/http"
 	"time"
 
 	"github.com/gorilla/websocket"
 )
 
-var upgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-}
-
 // Client is a middleman between the websocket connection and the hub.
 type RegisterClient struct {
 	hub *RegisterHub
@@ -28,6 +21,9 @@ type RegisterClient struct {
 	rpcAddr string
 	wsAddr  string
 
+	// 该service上已绑定的uid，只在hub.run中访问
+	uids map[string]bool
+
 	// Buffered channel of outbound messages.
 	send chan []byte
 }
@@ -37,6 +33,7 @@ type RegisterMessage struct {
 	Data      string   `json:"data"`
 	RpcAddr   string   `json:"rpc_addr"`
 	Addresses []string `json:"addresses"`
+	Uids      []string `json:"uids,omitempty"`
 }
 
 func (c *RegisterClient) read() {
@@ -46,11 +43,11 @@ func (c *RegisterClient) read() {
 
 	}()
 	// 设置超时时间，如果收到pong消息，则自动延长时间
-	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadLimit(registerMaxMessageSize)
 	c.conn.SetReadDeadline(time.Now().Add(registerPongWait))
 	c.conn.SetPongHandler(func(string) error {
 		c.conn.SetReadDeadline(time.Now().Add(registerPongWait))
-		log.Println("receive pong from ", c.conn.RemoteAddr().String())
+		c.hub.log(LogDebug, "receive pong", F("remote_addr", c.conn.RemoteAddr().String()))
 		return nil
 	})
 	for {
@@ -58,7 +55,7 @@ func (c *RegisterClient) read() {
 		err := c.conn.ReadJSON(&message)
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("error: %v", err)
+				c.hub.log(LogWarn, "service read error", F("rpc_addr", c.rpcAddr), F("error", err))
 			}
 			break
 		}
@@ -69,12 +66,16 @@ func (c *RegisterClient) read() {
 		//than other services can find this one
 		case registerActionConnect:
 			if !HostAddrCheck(message.RpcAddr) {
-				log.Println("error: rpc address error")
+				c.hub.log(LogWarn, "invalid rpc address", F("rpc_addr", message.RpcAddr))
 				break
 			}
 
 			c.rpcAddr = message.RpcAddr
 			c.hub.connect <- c
+		case registerActionListAddresses:
+			c.hub.list <- c
+		case registerActionUidOnline, registerActionUidOffline, registerActionSyncUids:
+			c.hub.presence <- &presenceUpdate{client: c, action: message.Action, uids: message.Uids}
 		}
 	}
 }
@@ -105,10 +106,10 @@ func (c *RegisterClient) write() {
 			//定时发送ping消息给客户端,client默认pinghander为返回pong消息
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
-				log.Println("can't send ping to ", c.conn.RemoteAddr().String())
+				c.hub.log(LogWarn, "send ping error", F("remote_addr", c.conn.RemoteAddr().String()), F("error", err))
 				return
 			}
-			log.Println("ping to ", c.conn.RemoteAddr().String())
+			c.hub.log(LogDebug, "ping", F("remote_addr", c.conn.RemoteAddr().String()))
 		}
 	}
 }
@@ -121,13 +122,18 @@ func (c *RegisterClient) close() {
 
 // serveWs handles websocket requests from the peer.
 func registerServeWs(hub *RegisterHub, w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     hub.origin.check,
+	}
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Println(err)
+		hub.log(LogDebug, "upgrade error", F("remote_addr", r.RemoteAddr), F("error", err))
 		return
 	}
-	log.Println("new client: ", conn.RemoteAddr().String())
-	client := &RegisterClient{hub: hub, conn: conn, send: make(chan []byte, 256)}
+	hub.log(LogInfo, "new service connection", F("remote_addr", conn.RemoteAddr().String()))
+	client := &RegisterClient{hub: hub, conn: conn, send: make(chan []byte, 256), uids: make(map[string]bool)}
 
 	// Allow collection of memory referenced by the caller by doing all work in
 	// new goroutines.
@@ -2,7 +2,6 @@ package websocket
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 )
 
@@ -10,14 +9,44 @@ type RegisterHub struct {
 	clients map[*RegisterClient]bool
 	connect chan *RegisterClient
 	close   chan *RegisterClient
+
+	// 每个uid所在的service
+	uidServices map[string]map[*RegisterClient]bool
+	presence    chan *presenceUpdate
+	list        chan *RegisterClient
+
+	origin *originChecker
+	// 监控数据，未开启时为nil
+	metrics *registerMetrics
+	logger  Logger
+}
+
+// 创建RegisterHub时的可选配置
+type RegisterOption func(r *RegisterHub)
+
+// service上报的uid变化
+type presenceUpdate struct {
+	client *RegisterClient
+	action string
+	uids   []string
 }
 
-func NewRegisterHub() *RegisterHub {
-	return &RegisterHub{
-		clients: make(map[*RegisterClient]bool),
-		connect: make(chan *RegisterClient),
-		close:   make(chan *RegisterClient),
+func NewRegisterHub(options ...RegisterOption) *RegisterHub {
+	r := &RegisterHub{
+		clients:     make(map[*RegisterClient]bool),
+		connect:     make(chan *RegisterClient),
+		close:       make(chan *RegisterClient),
+		uidServices: make(map[string]map[*RegisterClient]bool),
+		presence:    make(chan *presenceUpdate),
+		list:        make(chan *RegisterClient),
+		origin:      newOriginChecker(OriginPolicy{}),
+		logger:      defaultLogger(),
+	}
+	for _, option := range options {
+		option(r)
 	}
+	r.origin.logger = r.logger
+	return r
 }
 
 func (r *RegisterHub) run() {
@@ -25,10 +54,17 @@ func (r *RegisterHub) run() {
 		select {
 		case client := <-r.connect:
 			r.clients[client] = true
+			r.metrics.setNodes(len(r.clients))
 			r.broadcastServices()
 		case client := <-r.close:
-			delete(r.clients, client)
-			r.broadcastServices()
+			if _, ok := r.clients[client]; !ok {
+				break
+			}
+			r.removeClient(client)
+		case update := <-r.presence:
+			r.updatePresence(update)
+		case client := <-r.list:
+			r.sendAddresses(client)
 		}
 	}
 }
@@ -42,31 +78,145 @@ func (r *RegisterHub) broadcastServices() {
 		Action:    registerActionBroadcastAddr,
 		Addresses: addresses,
 	}
+
+	r.broadcast(&message)
+	r.log(LogDebug, "broadcast addresses", F("addresses", addresses))
+}
+
+// 只发送给查询的连接，该连接不会被当作service
+func (r *RegisterHub) sendAddresses(client *RegisterClient) {
+	addresses := make([]string, 0, len(r.clients))
+	for c := range r.clients {
+		addresses = append(addresses, c.rpcAddr)
+	}
+	msg, err := json.Marshal(RegisterMessage{Action: registerActionBroadcastAddr, Addresses: addresses})
+	if err != nil {
+		return
+	}
+	select {
+	case client.send <- msg:
+	default:
+	}
+}
+
+func (r *RegisterHub) broadcast(message *RegisterMessage) {
 	msg, err := json.Marshal(message)
 	if err != nil {
-		log.Println("error")
+		r.log(LogError, "marshal register message error", F("error", err))
 		return
 	}
+	r.metrics.broadcast(message.Action)
 
-	log.Println("starting broadcast addresses")
+	var dropped []*RegisterClient
 	for client := range r.clients {
 		select {
 		case client.send <- msg:
 		default:
-			delete(r.clients, client)
+			dropped = append(dropped, client)
 		}
 	}
-	log.Println("broadcast addresses success")
+	// 发送队列已满的service断开连接，与断开时的处理相同，service会重连并同步uid
+	for _, client := range dropped {
+		if _, ok := r.clients[client]; !ok {
+			continue
+		}
+		r.log(LogWarn, "service send queue full, drop it", F("rpc_addr", client.rpcAddr))
+		r.metrics.nodeDropped()
+		if client.conn != nil {
+			client.conn.Close()
+		}
+		r.removeClient(client)
+	}
+}
+
+// service断开或宕机，通知其他service，其上的uid全部下线
+func (r *RegisterHub) removeClient(client *RegisterClient) {
+	delete(r.clients, client)
+	r.metrics.setNodes(len(r.clients))
+	r.broadcastServices()
+	uids := make([]string, 0, len(client.uids))
+	for uid := range client.uids {
+		uids = append(uids, uid)
+	}
+	r.removeUids(client, uids)
+}
+
+func (r *RegisterHub) updatePresence(update *presenceUpdate) {
+	client := update.client
+	if _, ok := r.clients[client]; !ok {
+		return
+	}
+	switch update.action {
+	case registerActionUidOnline:
+		r.addUids(client, update.uids)
+	case registerActionUidOffline:
+		r.removeUids(client, update.uids)
+	case registerActionSyncUids:
+		// 以service上报的uid为准
+		uids := make(map[string]bool)
+		for _, uid := range update.uids {
+			uids[uid] = true
+		}
+		var removed []string
+		for uid := range client.uids {
+			if !uids[uid] {
+				removed = append(removed, uid)
+			}
+		}
+		r.removeUids(client, removed)
+		r.addUids(client, update.uids)
+	}
+}
+
+// uid在该service上线，第一个上线的service广播online
+func (r *RegisterHub) addUids(client *RegisterClient, uids []string) {
+	var online []string
+	for _, uid := range uids {
+		if client.uids[uid] {
+			continue
+		}
+		client.uids[uid] = true
+		if _, ok := r.uidServices[uid]; !ok {
+			r.uidServices[uid] = make(map[*RegisterClient]bool)
+			online = append(online, uid)
+		}
+		r.uidServices[uid][client] = true
+	}
+	if len(online) > 0 {
+		r.broadcast(&RegisterMessage{Action: registerActionBroadcastOnline, Uids: online})
+	}
+}
+
+// uid在该service下线，最后一个下线的service广播offline
+func (r *RegisterHub) removeUids(client *RegisterClient, uids []string) {
+	var offline []string
+	for _, uid := range uids {
+		if !client.uids[uid] {
+			continue
+		}
+		delete(client.uids, uid)
+		delete(r.uidServices[uid], client)
+		if len(r.uidServices[uid]) == 0 {
+			delete(r.uidServices, uid)
+			offline = append(offline, uid)
+		}
+	}
+	if len(offline) > 0 {
+		r.broadcast(&RegisterMessage{Action: registerActionBroadcastOffline, Uids: offline})
+	}
 }
 
 func (r *RegisterHub) Start(addr string) {
 	go r.run()
+	if r.metrics != nil {
+		http.HandleFunc(r.metrics.path, r.serveMetrics)
+	}
 	http.HandleFunc("/", func(writer http.ResponseWriter, request *http.Request) {
 		registerServeWs(r, writer, request)
 	})
-	log.Println("starting register...")
+	r.log(LogInfo, "starting register", F("addr", addr))
 	err := http.ListenAndServe(addr, nil)
 	if err != nil {
-		log.Fatal("ListenAndServe: ", err)
+		fatal(r.logger, "register listen error", err)
 	}
 }
@@ -0,0 +1,34 @@
+package websocket
+
+import (
+	"github.com/gorilla/websocket"
+	"net/url"
+	"time"
+)
+
+// 从register获取集群中所有service的rpc地址，供管理工具使用
+func ListServiceAddresses(registerAddr string, timeout time.Duration) ([]string, error) {
+	u := url.URL{Scheme: "ws", Host: registerAddr, Path: "/"}
+	dialer := *websocket.DefaultDialer
+	dialer.HandshakeTimeout = timeout
+	c, _, err := dialer.Dial(u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	c.SetWriteDeadline(time.Now().Add(timeout))
+	if err := c.WriteJSON(&RegisterMessage{Action: registerActionListAddresses}); err != nil {
+		return nil, err
+	}
+	c.SetReadDeadline(time.Now().Add(timeout))
+	for {
+		var message RegisterMessage
+		if err := c.ReadJSON(&message); err != nil {
+			return nil, err
+		}
+		if message.Action == registerActionBroadcastAddr {
+			return message.Addresses, nil
+		}
+	}
+}
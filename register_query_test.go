@@ -0,0 +1,66 @@
+package websocket
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegisterHub_sendAddresses(t *testing.T) {
+	t.Parallel()
+	r := NewRegisterHub()
+	for _, addr := range []string{"10.0.0.1:9101", "10.0.0.2:9101"} {
+		r.clients[newTestRegisterClient(r, addr, 1)] = true
+	}
+
+	query := newTestRegisterClient(r, "", 1)
+	r.sendAddresses(query)
+	var message RegisterMessage
+	json.Unmarshal(<-query.send, &message)
+	sort.Strings(message.Addresses)
+	if message.Action != registerActionBroadcastAddr || !reflect.DeepEqual(message.Addresses, []string{"10.0.0.1:9101", "10.0.0.2:9101"}) {
+		t.Errorf("sendAddresses() got = %+v", message)
+	}
+	// 只发送给查询的连接，不当作service
+	for client := range r.clients {
+		if len(client.send) != 0 {
+			t.Errorf("sendAddresses() sent to service %v", client.rpcAddr)
+		}
+	}
+
+	// 发送队列已满时丢弃，不阻塞
+	query.send <- []byte("full")
+	r.sendAddresses(query)
+	if len(query.send) != 1 {
+		t.Errorf("sendAddresses() to a full queue got %v messages", len(query.send))
+	}
+}
+
+func TestListServiceAddresses(t *testing.T) {
+	t.Parallel()
+	r := NewRegisterHub()
+	r.clients[newTestRegisterClient(r, "10.0.0.1:9101", 16)] = true
+	go r.run()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		registerServeWs(r, w, req)
+	}))
+	defer server.Close()
+	addr := strings.TrimPrefix(server.URL, "http://")
+
+	// 查询的连接不会被当作service，多次查询结果相同
+	for i := 0; i < 2; i++ {
+		addresses, err := ListServiceAddresses(addr, 5*time.Second)
+		if err != nil || !reflect.DeepEqual(addresses, []string{"10.0.0.1:9101"}) {
+			t.Errorf("ListServiceAddresses() got = %v, err = %v", addresses, err)
+		}
+	}
+
+	if _, err := ListServiceAddresses("127.0.0.1:1", time.Second); err == nil {
+		t.Errorf("ListServiceAddresses() without register got err = nil")
+	}
+}
@@ -0,0 +1,142 @@
+package websocket
+
+import (
+	"encoding/json"
+	pb "github.com/bin-x/websocket/proto"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// 未配置WithReliableDelivery时使用
+	defaultAckTimeout = 10 * time.Second
+	defaultMaxUnacked = 1024
+)
+
+// 可靠发送，客户端收到
+//
+//	{"type":"reliable","id":"1","data":message}
+//
+// 需回复 {"type":"ack","id":"1"}，确认该序号及之前的所有消息。
+// 未确认的消息保存在会话中，超时或恢复会话后重新发送，客户端需要根据id去重。
+// message不是json时data为json字符串。
+func Reliable() SendOption {
+	return func(request *pb.ServiceRequest) {
+		request.Reliable = true
+	}
+}
+
+// 设置可靠发送的重发时间和每个client最多保存的未确认消息数，超出时丢弃最早的消息
+func WithReliableDelivery(ackTimeout time.Duration, maxUnacked int) ServiceOption {
+	return func(sh *ServiceHub) {
+		sh.ackTimeout = ackTimeout
+		sh.maxUnacked = maxUnacked
+	}
+}
+
+type unackedMessage struct {
+	seq     uint64
+	message []byte
+	sent    time.Time
+}
+
+// 每个会话未确认的消息
+type outbox struct {
+	mu       sync.Mutex
+	nextSeq  uint64
+	messages []*unackedMessage
+}
+
+// 添加一条消息，超出max时丢弃最早的消息，dropped为丢弃的序号，未丢弃时为0
+func (o *outbox) add(message []byte, max int, now time.Time) (wrapped []byte, dropped uint64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.nextSeq++
+	m := &unackedMessage{seq: o.nextSeq, message: wrapReliable(o.nextSeq, message), sent: now}
+	o.messages = append(o.messages, m)
+	if max > 0 && len(o.messages) > max {
+		dropped = o.messages[0].seq
+		o.messages = o.messages[1:]
+	}
+	return m.message, dropped
+}
+
+// 确认seq及之前的消息
+func (o *outbox) ack(seq uint64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	i := 0
+	for i < len(o.messages) && o.messages[i].seq <= seq {
+		i++
+	}
+	o.messages = o.messages[i:]
+}
+
+// 返回发送时间超过timeout的消息，timeout为0时返回全部
+func (o *outbox) expired(timeout time.Duration, now time.Time) [][]byte {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var messages [][]byte
+	for _, m := range o.messages {
+		if now.Sub(m.sent) >= timeout {
+			m.sent = now
+			messages = append(messages, m.message)
+		}
+	}
+	return messages
+}
+
+func wrapReliable(seq uint64, message []byte) []byte {
+	var data interface{} = json.RawMessage(message)
+	if !json.Valid(message) {
+		data = string(message)
+	}
+	wrapped, _ := MarshalEnvelope(EnvelopeTypeReliable, strconv.FormatUint(seq, 10), data)
+	return wrapped
+}
+
+// 发送消息，reliable为true时保存到未确认消息中
+func (c *Client) deliver(message []byte, reliable bool) bool {
+	if !reliable {
+		return c.push(message)
+	}
+	select {
+	case <-c.closed:
+		return false
+	default:
+	}
+	wrapped, dropped := c.outbox.add(message, c.hub.maxUnacked, time.Now())
+	if dropped > 0 {
+		c.hub.log(LogWarn, "too many unacked messages, drop the oldest", F("client_id", c.id), F("seq", dropped))
+	}
+	// 队列已满时等待超时重发
+	c.push(wrapped)
+	return true
+}
+
+// 重新发送未确认的消息，all为true时不考虑超时
+func (c *Client) retransmit(all bool) {
+	timeout := c.hub.ackTimeout
+	if all {
+		timeout = 0
+	}
+	for _, message := range c.outbox.expired(timeout, time.Now()) {
+		c.push(message)
+	}
+}
+
+// 处理客户端的确认消息，不是确认消息时返回false
+func (c *Client) handleAck(message []byte) bool {
+	if len(message) == 0 || message[0] != '{' {
+		return false
+	}
+	var envelope Envelope
+	if err := json.Unmarshal(message, &envelope); err != nil || envelope.Type != EnvelopeTypeAck {
+		return false
+	}
+	if seq, err := strconv.ParseUint(envelope.Id, 10, 64); err == nil {
+		c.outbox.ack(seq)
+	}
+	return true
+}
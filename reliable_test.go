@@ -0,0 +1,56 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOutbox(t *testing.T) {
+	t.Parallel()
+	o := &outbox{}
+	now := time.Now()
+	first, _ := o.add([]byte(`{"a":1}`), 3, now)
+	if string(first) != `{"type":"reliable","id":"1","data":{"a":1}}` {
+		t.Errorf("add() got = %s", first)
+	}
+	if got, _ := o.add([]byte("text"), 3, now); string(got) != `{"type":"reliable","id":"2","data":"text"}` {
+		t.Errorf("add() got = %s", got)
+	}
+	o.add([]byte("3"), 3, now.Add(time.Second))
+	if _, dropped := o.add([]byte("4"), 3, now.Add(time.Second)); dropped != 1 || len(o.messages) != 3 || o.messages[0].seq != 2 {
+		t.Errorf("add() should drop the oldest message, got %v", len(o.messages))
+	}
+
+	if got := o.expired(time.Second, now.Add(1500*time.Millisecond)); len(got) != 1 {
+		t.Errorf("expired() got %v messages, want 1", len(got))
+	}
+	o.ack(3)
+	if got := o.expired(0, now.Add(2*time.Second)); len(got) != 1 || o.messages[0].seq != 4 {
+		t.Errorf("ack() should remove messages up to seq 3, got %v", len(got))
+	}
+}
+
+func TestClient_handleAck(t *testing.T) {
+	t.Parallel()
+	c := &Client{}
+	c.outbox.add([]byte("1"), 0, time.Now())
+	tests := []struct {
+		name    string
+		message string
+		want    bool
+	}{
+		{"ack", `{"type":"ack","id":"1"}`, true},
+		{"other envelope", `{"type":"chat","id":"1"}`, false},
+		{"not json", `hello`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.handleAck([]byte(tt.message)); got != tt.want {
+				t.Errorf("handleAck() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if len(c.outbox.messages) != 0 {
+		t.Errorf("handleAck() should ack seq 1")
+	}
+}
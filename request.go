@@ -0,0 +1,189 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	pb "github.com/bin-x/websocket/proto"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RequestClient的ctx未设置超时时间时使用
+const requestClientTimeout = 30 * time.Second
+
+var ErrRequestTimeout = errors.New("request timeout")
+
+var currentRequestId uint32 = 0
+
+// 发起请求的服务等待客户端回复的请求
+type pendingRequest struct {
+	clientId string
+	reply    chan *Envelope
+}
+
+type pendingRequests struct {
+	mu       sync.Mutex
+	requests map[string]*pendingRequest
+}
+
+func newPendingRequests() *pendingRequests {
+	return &pendingRequests{requests: make(map[string]*pendingRequest)}
+}
+
+func (p *pendingRequests) add(id, clientId string) chan *Envelope {
+	reply := make(chan *Envelope, 1)
+	p.mu.Lock()
+	p.requests[id] = &pendingRequest{clientId: clientId, reply: reply}
+	p.mu.Unlock()
+	return reply
+}
+
+func (p *pendingRequests) remove(id string) {
+	p.mu.Lock()
+	delete(p.requests, id)
+	p.mu.Unlock()
+}
+
+// 只接受请求的client的回复
+func (p *pendingRequests) resolve(id, clientId string, envelope *Envelope) bool {
+	p.mu.Lock()
+	request, ok := p.requests[id]
+	if ok && request.clientId == clientId {
+		delete(p.requests, id)
+	} else {
+		ok = false
+	}
+	p.mu.Unlock()
+	if ok {
+		request.reply <- envelope
+	}
+	return ok
+}
+
+// 客户端所在的服务记录的请求，回复只接受该client的，并转发给发起请求的服务
+type requestRoute struct {
+	clientId string
+	origin   string
+}
+
+type requestRoutes struct {
+	mu     sync.Mutex
+	routes map[string]requestRoute
+}
+
+func newRequestRoutes() *requestRoutes {
+	return &requestRoutes{routes: make(map[string]requestRoute)}
+}
+
+// 超时后发起请求的服务不再等待，删除记录
+func (r *requestRoutes) add(id string, route requestRoute, timeout time.Duration) {
+	r.mu.Lock()
+	r.routes[id] = route
+	r.mu.Unlock()
+	time.AfterFunc(timeout, func() {
+		r.remove(id)
+	})
+}
+
+func (r *requestRoutes) remove(id string) {
+	r.mu.Lock()
+	delete(r.routes, id)
+	r.mu.Unlock()
+}
+
+// 取出clientId发来的回复对应的请求，id未知或不是发给该client的请求时返回false
+func (r *requestRoutes) take(id, clientId string) (requestRoute, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	route, ok := r.routes[id]
+	if !ok || route.clientId != clientId {
+		return requestRoute{}, false
+	}
+	delete(r.routes, id)
+	return route, true
+}
+
+// 请求id与clientId格式相同，包含发起请求的服务地址，回复可以据此转发
+func (sh *ServiceHub) newRequestId() string {
+	id := atomic.AddUint32(&currentRequestId, 1)
+	return AddressToClientId(sh.lanIp, sh.rpcPort, id)
+}
+
+// 处理客户端对RequestClient的回复，不是回复时返回false，交给Application处理。
+// 只接受发给该client的请求的回复，转发给发送请求时记录的服务。
+func (sh *ServiceHub) handleResponse(clientId string, message []byte) bool {
+	if sh.requestRoutes == nil || len(message) == 0 || message[0] != '{' {
+		return false
+	}
+	var envelope Envelope
+	if err := json.Unmarshal(message, &envelope); err != nil || envelope.Type != EnvelopeTypeResponse {
+		return false
+	}
+	route, ok := sh.requestRoutes.take(envelope.Id, clientId)
+	if !ok {
+		sh.log(LogDebug, "drop unknown response", F("client_id", clientId), F("request_id", envelope.Id))
+		return true
+	}
+
+	if route.origin == sh.rpcAddr() {
+		sh.requests.resolve(envelope.Id, clientId, &envelope)
+		return true
+	}
+	// 请求由其他服务发起，转发给该服务
+	go func() {
+		request := &pb.ServiceRequest{RequestId: envelope.Id, ClientId: clientId, Message: message}
+		if _, err := Api.callNode(route.origin, "DeliverResponse", context.Background(), request); err != nil {
+			sh.log(LogWarn, "deliver response error", F("addr", route.origin), F("error", err))
+		}
+	}()
+	return true
+}
+
+// 发送请求给客户端并等待回复。
+// 客户端收到 {"type":"request","id":"xxx","data":payload}，
+// 需回复 {"type":"response","id":"xxx","data":...}，出错时回复 {"type":"response","id":"xxx","error":{"code":"xxx","message":"xxx"}}。
+// payload会被json编码，可以使用json.RawMessage发送已编码的数据。
+// ctx未设置超时时间时默认30秒超时。
+func (s *ServiceApi) RequestClient(ctx context.Context, clientId string, payload interface{}) (json.RawMessage, error) {
+	addr, err := nodeOfClientId(clientId)
+	if err != nil {
+		return nil, err
+	}
+	id := s.hub.newRequestId()
+	message, err := MarshalEnvelope(EnvelopeTypeRequest, id, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, requestClientTimeout)
+		defer cancel()
+	}
+
+	reply := s.hub.requests.add(id, clientId)
+	defer s.hub.requests.remove(id)
+
+	response, err := s.callNode(addr, "SendRequest", ctx, &pb.ServiceRequest{ClientId: clientId, RequestId: id, Message: message})
+	if err != nil {
+		return nil, err
+	}
+	if !response.Success {
+		return nil, ErrClientNotFound
+	}
+
+	select {
+	case envelope := <-reply:
+		if envelope.Error != nil {
+			return nil, envelope.Error
+		}
+		return envelope.Data, nil
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, ErrRequestTimeout
+		}
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,60 @@
+package websocket
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// 开启会话恢复。客户端连接后先收到
+//
+//	{"type":"session","data":{"client_id":"xxx","resume_token":"xxx","resumed":false}}
+//
+// 断线后在grace时间内使用 ?resume_token=xxx 重新连接，可以恢复原来的clientId、uid、分组和info，
+// 并收到断线期间的消息。OnClose在grace时间过后仍未恢复时才调用。
+// 恢复只能在原来的服务上进行，负载均衡需要保证同一客户端连接到同一服务。
+func WithSessionResume(grace time.Duration) ServiceOption {
+	return func(sh *ServiceHub) {
+		sh.resumeGrace = grace
+	}
+}
+
+// 开启会话恢复时，连接或恢复后首先发送给客户端的会话信息
+type SessionData struct {
+	ClientId    string `json:"client_id"`
+	ResumeToken string `json:"resume_token"`
+	Resumed     bool   `json:"resumed"`
+}
+
+type resumeRequest struct {
+	token string
+	reply chan *Client
+}
+
+// token格式：clientId.随机字符串
+func newResumeToken(clientId string) string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return clientId + "." + hex.EncodeToString(b)
+}
+
+// 查找token对应的会话，由run负责读取clients
+func (sh *ServiceHub) findResumable(token string) *Client {
+	reply := make(chan *Client)
+	sh.resume <- &resumeRequest{token: token, reply: reply}
+	return <-reply
+}
+
+func (sh *ServiceHub) lookupResumable(token string) *Client {
+	i := strings.IndexByte(token, '.')
+	if i < 0 {
+		return nil
+	}
+	client, ok := sh.clients[token[:i]]
+	if !ok || subtle.ConstantTimeCompare([]byte(client.resumeToken), []byte(token)) != 1 {
+		return nil
+	}
+	return client
+}
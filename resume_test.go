@@ -0,0 +1,189 @@
+package websocket
+
+import (
+	"encoding/json"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServiceHub_lookupResumable(t *testing.T) {
+	t.Parallel()
+	hub := CreateHub()
+	client := hub.clients["1"]
+	client.resumeToken = newResumeToken(client.id)
+
+	tests := []struct {
+		name  string
+		token string
+		want  *Client
+	}{
+		{"valid", client.resumeToken, client},
+		{"wrong secret", client.id + ".00", nil},
+		{"unknown client", "9." + client.resumeToken[2:], nil},
+		{"no resume token", "2.00", nil},
+		{"malformed", "xxx", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hub.lookupResumable(tt.token); got != tt.want {
+				t.Errorf("lookupResumable() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type resumeApp struct {
+	testApp
+	closed chan string
+}
+
+func (a *resumeApp) OnClose(clientId string) {
+	a.closed <- clientId
+}
+
+func newResumeHub(grace time.Duration) (*ServiceHub, *resumeApp) {
+	hub := CreateHub()
+	app := &resumeApp{closed: make(chan string, 1)}
+	hub.application = app
+	hub.resumeGrace = grace
+	hub.lanIp = "127.0.0.1"
+	hub.rpcPort = 9101
+	return hub, app
+}
+
+// 返回服务端和客户端两端的连接
+func newTestConn(t *testing.T) (*websocket.Conn, *websocket.Conn) {
+	conns := make(chan *websocket.Conn, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Upgrade() error = %v", err)
+			return
+		}
+		conns <- conn
+	}))
+	t.Cleanup(server.Close)
+	peer, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { peer.Close() })
+	return <-conns, peer
+}
+
+func readTestEnvelope(t *testing.T, conn *websocket.Conn) (*Envelope, string) {
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, message, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+	var envelope Envelope
+	json.Unmarshal(message, &envelope)
+	return &envelope, string(message)
+}
+
+func readSession(t *testing.T, conn *websocket.Conn) SessionData {
+	envelope, message := readTestEnvelope(t, conn)
+	var session SessionData
+	if envelope.Type != EnvelopeTypeSession || json.Unmarshal(envelope.Data, &session) != nil {
+		t.Fatalf("read session got = %v", message)
+	}
+	return session
+}
+
+func TestClient_resume(t *testing.T) {
+	t.Parallel()
+	hub, app := newResumeHub(time.Minute)
+	server, peer := newTestConn(t)
+	client := NewServiceClient(hub, server)
+	hub.connect <- client
+	go client.run()
+
+	session := readSession(t, peer)
+	if session.ClientId != client.id || session.Resumed || session.ResumeToken == "" {
+		t.Errorf("session got = %+v", session)
+	}
+	// 连接时发送但未确认的消息
+	client.deliver([]byte(`{"n":1}`), true)
+	if envelope, message := readTestEnvelope(t, peer); envelope.Type != EnvelopeTypeReliable || envelope.Id != "1" {
+		t.Errorf("reliable message got = %v", message)
+	}
+
+	// 断线后进入等待恢复，不调用OnClose
+	client.disconnected(server, CloseEvent{Code: websocket.CloseGoingAway, Initiator: CloseByPeer})
+	waitClient(client)
+	client.push([]byte("offline"))
+	select {
+	case id := <-app.closed:
+		t.Errorf("OnClose(%v) called before grace expired", id)
+	default:
+	}
+
+	resumable := hub.findResumable(session.ResumeToken)
+	if resumable != client {
+		t.Fatalf("findResumable() got = %v, want %v", resumable, client)
+	}
+	server2, peer2 := newTestConn(t)
+	if !resumable.resumeWith(server2) {
+		t.Fatalf("resumeWith() got = false")
+	}
+
+	// 恢复后首先收到会话信息，然后是断线期间的消息和重发的未确认消息
+	resumed := readSession(t, peer2)
+	if resumed.ClientId != client.id || !resumed.Resumed || resumed.ResumeToken != session.ResumeToken {
+		t.Errorf("resumed session got = %+v", resumed)
+	}
+	if _, message := readTestEnvelope(t, peer2); message != "offline" {
+		t.Errorf("offline message got = %v", message)
+	}
+	if envelope, message := readTestEnvelope(t, peer2); envelope.Type != EnvelopeTypeReliable || envelope.Id != "1" || string(envelope.Data) != `{"n":1}` {
+		t.Errorf("retransmitted message got = %v", message)
+	}
+
+	// 旧连接的断开事件不影响恢复后的会话
+	client.disconnected(server, CloseEvent{Code: websocket.CloseAbnormalClosure, Initiator: CloseByPeer})
+	client.push([]byte("after"))
+	if _, message := readTestEnvelope(t, peer2); message != "after" {
+		t.Errorf("message after resume got = %v", message)
+	}
+
+	client.requestClose(CloseEvent{Code: websocket.CloseNormalClosure, Initiator: CloseByServer})
+	<-client.closed
+	if id := <-app.closed; id != client.id {
+		t.Errorf("OnClose() got = %v, want %v", id, client.id)
+	}
+}
+
+func TestClient_resumeGraceExpired(t *testing.T) {
+	t.Parallel()
+	hub, app := newResumeHub(20 * time.Millisecond)
+	server, peer := newTestConn(t)
+	client := NewServiceClient(hub, server)
+	hub.connect <- client
+	go client.run()
+	session := readSession(t, peer)
+
+	client.disconnected(server, CloseEvent{Code: websocket.CloseGoingAway, Initiator: CloseByPeer})
+	select {
+	case id := <-app.closed:
+		if id != client.id {
+			t.Errorf("OnClose() got = %v, want %v", id, client.id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("OnClose() not called after grace expired")
+	}
+	<-client.closed
+
+	// 过期后token不能再使用
+	if resumable := hub.findResumable(session.ResumeToken); resumable != nil {
+		t.Errorf("findResumable() after expired got = %v, want nil", resumable)
+	}
+	server2, _ := newTestConn(t)
+	if client.resumeWith(server2) {
+		t.Errorf("resumeWith() after expired got = true")
+	}
+}
@@ -0,0 +1,180 @@
+package websocket
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+const (
+	ErrorCodeBadRequest  = "bad_request"
+	ErrorCodeBadPayload  = "bad_payload"
+	ErrorCodeUnknownType = "unknown_type"
+	ErrorCodeInternal    = "internal_error"
+)
+
+var (
+	stringType = reflect.TypeOf("")
+	errorType  = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// 按消息的type分发消息的Application，可以直接传给NewServiceHub。
+// 客户端发送的消息需为Envelope格式，data会被解码为handler的参数类型。
+//	router := NewRouter()
+//	router.Handle("chat", func(clientId string, msg *ChatMessage) error {...})
+//	hub := NewServiceHub(registerAddr, rpcPort, lanIp, router)
+type Router struct {
+	handlers  map[string]*routeHandler
+	fallback  func(clientId string, envelope *Envelope) error
+	onConnect func(clientId string)
+	onClose   func(clientId string)
+
+	send func(clientId string, message []byte)
+}
+
+type routeHandler struct {
+	fn          reflect.Value
+	payloadType reflect.Type
+	hasResult   bool
+}
+
+func NewRouter() *Router {
+	return &Router{
+		handlers: make(map[string]*routeHandler),
+		send: func(clientId string, message []byte) {
+			Api.SendToClient(clientId, message)
+		},
+	}
+}
+
+// 注册某个type的handler，handler支持以下格式，T为data解码后的类型：
+//	func(clientId string) error
+//	func(clientId string, payload T) error
+//	func(clientId string, payload T) (result R, err error)
+// 返回的error会以错误消息发送给客户端，返回的result不为nil时以相同的type和id回复给客户端。
+func (r *Router) Handle(msgType string, handler interface{}) {
+	fn := reflect.ValueOf(handler)
+	t := fn.Type()
+	if t.Kind() != reflect.Func || t.NumIn() < 1 || t.NumIn() > 2 || t.In(0) != stringType {
+		panic("websocket: invalid handler for " + msgType)
+	}
+	if t.NumOut() < 1 || t.NumOut() > 2 || t.Out(t.NumOut()-1) != errorType {
+		panic("websocket: handler for " + msgType + " must return error")
+	}
+	if t.NumOut() == 2 && t.NumIn() != 2 {
+		panic("websocket: handler for " + msgType + " with result must have payload")
+	}
+
+	h := &routeHandler{fn: fn, hasResult: t.NumOut() == 2}
+	if t.NumIn() == 2 {
+		h.payloadType = t.In(1)
+	}
+	r.handlers[msgType] = h
+}
+
+// 没有对应handler时调用，未设置时返回unknown_type错误给客户端
+func (r *Router) Fallback(handler func(clientId string, envelope *Envelope) error) {
+	r.fallback = handler
+}
+
+func (r *Router) HandleConnect(handler func(clientId string)) {
+	r.onConnect = handler
+}
+
+func (r *Router) HandleClose(handler func(clientId string)) {
+	r.onClose = handler
+}
+
+func (r *Router) OnConnect(clientId string) {
+	if r.onConnect != nil {
+		r.onConnect(clientId)
+	}
+}
+
+func (r *Router) OnClose(clientId string) {
+	if r.onClose != nil {
+		r.onClose(clientId)
+	}
+}
+
+func (r *Router) OnMessage(clientId string, message []byte) {
+	var envelope Envelope
+	if err := json.Unmarshal(message, &envelope); err != nil || envelope.Type == "" {
+		r.sendError(clientId, "", &EnvelopeError{Code: ErrorCodeBadRequest, Message: "invalid envelope"})
+		return
+	}
+
+	h, ok := r.handlers[envelope.Type]
+	if !ok {
+		if r.fallback == nil {
+			r.sendError(clientId, envelope.Id, &EnvelopeError{Code: ErrorCodeUnknownType, Message: "unknown type " + envelope.Type})
+			return
+		}
+		if err := r.fallback(clientId, &envelope); err != nil {
+			r.sendError(clientId, envelope.Id, toEnvelopeError(err))
+		}
+		return
+	}
+
+	in := []reflect.Value{reflect.ValueOf(clientId)}
+	if h.payloadType != nil {
+		payload, err := decodePayload(envelope.Data, h.payloadType)
+		if err != nil {
+			r.sendError(clientId, envelope.Id, &EnvelopeError{Code: ErrorCodeBadPayload, Message: err.Error()})
+			return
+		}
+		in = append(in, payload)
+	}
+
+	out := h.fn.Call(in)
+	if err, _ := out[len(out)-1].Interface().(error); err != nil {
+		r.sendError(clientId, envelope.Id, toEnvelopeError(err))
+		return
+	}
+	if h.hasResult && !isNil(out[0]) {
+		reply, err := MarshalEnvelope(envelope.Type, envelope.Id, out[0].Interface())
+		if err != nil {
+			r.sendError(clientId, envelope.Id, toEnvelopeError(err))
+			return
+		}
+		r.send(clientId, reply)
+	}
+}
+
+func (r *Router) sendError(clientId, id string, err *EnvelopeError) {
+	r.send(clientId, marshalErrorEnvelope(id, err))
+}
+
+// 将data解码为t类型，t为指针时解码到新建的对象
+func decodePayload(data json.RawMessage, t reflect.Type) (reflect.Value, error) {
+	ptr := t.Kind() == reflect.Ptr
+	var v reflect.Value
+	if ptr {
+		v = reflect.New(t.Elem())
+	} else {
+		v = reflect.New(t)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, v.Interface()); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	if ptr {
+		return v, nil
+	}
+	return v.Elem(), nil
+}
+
+func toEnvelopeError(err error) *EnvelopeError {
+	if e, ok := err.(*EnvelopeError); ok {
+		return e
+	}
+	return &EnvelopeError{Code: ErrorCodeInternal, Message: err.Error()}
+}
+
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type chatMessage struct {
+	Room string `json:"room"`
+	Text string `json:"text"`
+}
+
+func newTestRouter() (*Router, map[string][]Envelope) {
+	sent := make(map[string][]Envelope)
+	router := NewRouter()
+	router.send = func(clientId string, message []byte) {
+		var envelope Envelope
+		json.Unmarshal(message, &envelope)
+		sent[clientId] = append(sent[clientId], envelope)
+	}
+	return router, sent
+}
+
+func TestRouter_OnMessage(t *testing.T) {
+	t.Parallel()
+	var got *chatMessage
+	router, sent := newTestRouter()
+	router.Handle("chat", func(clientId string, msg *chatMessage) error {
+		got = msg
+		return nil
+	})
+	router.Handle("echo", func(clientId string, msg chatMessage) (interface{}, error) {
+		return msg, nil
+	})
+	router.Handle("forbidden", func(clientId string) error {
+		return &EnvelopeError{Code: "forbidden", Message: "not allowed"}
+	})
+	router.Handle("fail", func(clientId string) error {
+		return errors.New("boom")
+	})
+
+	router.OnMessage("1", []byte(`{"type":"chat","data":{"room":"x","text":"hi"}}`))
+	if got == nil || got.Room != "x" || got.Text != "hi" {
+		t.Errorf("Router chat handler got = %v", got)
+	}
+	if len(sent["1"]) != 0 {
+		t.Errorf("Router chat handler sent = %v, want nothing", sent["1"])
+	}
+
+	tests := []struct {
+		message string
+		want    Envelope
+	}{
+		{
+			message: `{"type":"echo","id":"a","data":{"room":"y"}}`,
+			want:    Envelope{Type: "echo", Id: "a", Data: json.RawMessage(`{"room":"y","text":""}`)},
+		},
+		{
+			message: `{"type":"forbidden","id":"b"}`,
+			want:    Envelope{Type: "error", Id: "b", Error: &EnvelopeError{Code: "forbidden", Message: "not allowed"}},
+		},
+		{
+			message: `{"type":"fail","id":"c"}`,
+			want:    Envelope{Type: "error", Id: "c", Error: &EnvelopeError{Code: ErrorCodeInternal, Message: "boom"}},
+		},
+		{
+			message: `{"type":"chat","id":"d","data":"text"}`,
+			want:    Envelope{Type: "error", Id: "d", Error: &EnvelopeError{Code: ErrorCodeBadPayload}},
+		},
+		{
+			message: `{"type":"unknown","id":"e"}`,
+			want:    Envelope{Type: "error", Id: "e", Error: &EnvelopeError{Code: ErrorCodeUnknownType}},
+		},
+		{
+			message: `not json`,
+			want:    Envelope{Type: "error", Error: &EnvelopeError{Code: ErrorCodeBadRequest}},
+		},
+	}
+
+	for _, tt := range tests {
+		router.OnMessage("2", []byte(tt.message))
+		replies := sent["2"]
+		if len(replies) == 0 {
+			t.Errorf("Router.OnMessage(%v) sent nothing", tt.message)
+			continue
+		}
+		reply := replies[len(replies)-1]
+		if reply.Type != tt.want.Type || reply.Id != tt.want.Id || string(reply.Data) != string(tt.want.Data) {
+			t.Errorf("Router.OnMessage(%v) got = %+v, want %+v", tt.message, reply, tt.want)
+		}
+		if tt.want.Error != nil && (reply.Error == nil || reply.Error.Code != tt.want.Error.Code) {
+			t.Errorf("Router.OnMessage(%v) got error = %v, want %v", tt.message, reply.Error, tt.want.Error)
+		}
+	}
+}
+
+func TestRouter_Fallback(t *testing.T) {
+	t.Parallel()
+	var got string
+	router, sent := newTestRouter()
+	router.Fallback(func(clientId string, envelope *Envelope) error {
+		got = envelope.Type
+		return nil
+	})
+	router.OnMessage("1", []byte(`{"type":"other"}`))
+	if got != "other" || len(sent["1"]) != 0 {
+		t.Errorf("Router fallback got = %v, sent = %v", got, sent["1"])
+	}
+}
@@ -0,0 +1,46 @@
+package websocket
+
+import (
+	pb "github.com/bin-x/websocket/proto"
+)
+
+// 发送消息时的可选参数
+type SendOption func(request *pb.ServiceRequest)
+
+// 发送时排除这些client，如发送聊天消息时不回显给发送者
+func ExcludeClientIds(clientIds ...string) SendOption {
+	return func(request *pb.ServiceRequest) {
+		request.ExcludeClientIds = append(request.ExcludeClientIds, clientIds...)
+	}
+}
+
+// 发送时排除绑定了这些uid的client
+func ExcludeUids(uids ...string) SendOption {
+	return func(request *pb.ServiceRequest) {
+		request.ExcludeUids = append(request.ExcludeUids, uids...)
+	}
+}
+
+func applySendOptions(request *pb.ServiceRequest, options []SendOption) {
+	for _, option := range options {
+		option(request)
+	}
+}
+
+// 根据请求中的排除列表判断client是否需要跳过
+func excludeFilter(request *pb.ServiceRequest) func(client *Client) bool {
+	if len(request.ExcludeClientIds) == 0 && len(request.ExcludeUids) == 0 {
+		return func(client *Client) bool { return false }
+	}
+	clientIds := make(map[string]bool, len(request.ExcludeClientIds))
+	for _, clientId := range request.ExcludeClientIds {
+		clientIds[clientId] = true
+	}
+	uids := make(map[string]bool, len(request.ExcludeUids))
+	for _, uid := range request.ExcludeUids {
+		uids[uid] = true
+	}
+	return func(client *Client) bool {
+		return clientIds[client.id] || (client.uid != "" && uids[client.uid])
+	}
+}
@@ -7,11 +7,11 @@ import (
 	"github.com/gorilla/websocket"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/keepalive"
-	"log"
 	"net"
 	"net/http"
 	"net/url"
 	"strconv"
+	"sync/atomic"
 	"time"
 )
 
@@ -32,19 +32,89 @@ type ServiceHub struct {
 	addServices   chan map[string]*serviceRpcClient
 	deleteService chan string
 
-	disbandGroup chan string
-	joinGroup    chan map[*Client]string
-	leaveGroup   chan map[*Client]string
-	bindUid      chan map[*Client]string
-	unbindUid    chan *Client
+	disbandGroup   chan string
+	joinGroup      chan map[*Client]string
+	leaveGroup     chan map[*Client]string
+	leaveAllGroups chan *Client
+	bindUid        chan map[*Client]string
+	unbindUid      chan *Client
 
 	application   Application
 	otherAddress  map[string]bool
 	otherServices map[string]*serviceRpcClient
+
+	// 发送给register的消息，队列已满丢弃后通过registerResync重新同步uid
+	registerSend     chan *RegisterMessage
+	registerResync   chan struct{}
+	getUids          chan chan []string
+	presenceEvents   chan PresenceEvent
+	presenceHandlers []func(event PresenceEvent)
+	groupMessages    chan *groupMessage
+
+	// info的本地索引，未配置时为nil
+	infoIndex *infoIndex
+
+	// 本服务发起的RequestClient
+	requests *pendingRequests
+	// 发给本服务client的RequestClient
+	requestRoutes *requestRoutes
+
+	// 离线消息，未配置时为nil
+	messageStore MessageStore
+	// 分组历史消息，未配置时为nil
+	history *groupHistory
+
+	// 断线后保留会话的时间，0为不保留
+	resumeGrace time.Duration
+	resume      chan *resumeRequest
+
+	// 可靠发送的重发时间和未确认消息上限
+	ackTimeout time.Duration
+	maxUnacked int
+
+	// 客户端消息限流，按顺序检查
+	rateLimiters []*rateLimiter
+	// 连接数限制，未配置时为nil
+	admission *admission
+
+	idleTimeout     time.Duration
+	idleResetOnSend bool
+	maxLifetime     time.Duration
+	// 发送队列已满时关闭连接
+	closeSlowConsumers bool
+
+	// 允许的websocket来源
+	origin *originChecker
+
+	logger Logger
+
+	callInterceptors    []CallInterceptor
+	handlerInterceptors []HandlerInterceptor
+	// 客户端消息的中间件
+	messageMiddlewares []MessageMiddleware
+	// Application的方法panic时的处理方式
+	panicPolicy PanicPolicy
+
+	// 监控数据，未开启时为nil
+	metrics  *serviceMetrics
+	getStats chan chan hubStats
+
+	// 与register的连接、rpc服务的状态，1为正常；调用Drain后draining为1
+	registerConnected int32
+	rpcServing        int32
+	draining          int32
+	adminToken        string
+	getClients        chan chan []*Client
+	getClient         chan *clientRequest
+}
+
+type clientRequest struct {
+	clientId string
+	reply    chan *Client
 }
 
-func NewServiceHub(registerAddr string, rpcPort uint16, lanIp string, application Application) *ServiceHub {
-	return &ServiceHub{
+func NewServiceHub(registerAddr string, rpcPort uint16, lanIp string, application Application, options ...ServiceOption) *ServiceHub {
+	sh := &ServiceHub{
 		registerAddr: registerAddr,
 		rpcPort:      rpcPort,
 		lanIp:        lanIp,
@@ -66,7 +136,34 @@ func NewServiceHub(registerAddr string, rpcPort uint16, lanIp string, applicatio
 		unbindUid:    make(chan *Client),
 		application:  application,
 		otherAddress: make(map[string]bool),
+
+		leaveAllGroups: make(chan *Client),
+
+		registerSend:   make(chan *RegisterMessage, 1024),
+		registerResync: make(chan struct{}, 1),
+		getUids:        make(chan chan []string),
+		presenceEvents: make(chan PresenceEvent, 1024),
+		groupMessages:  make(chan *groupMessage),
+		requests:       newPendingRequests(),
+		requestRoutes:  newRequestRoutes(),
+		resume:         make(chan *resumeRequest),
+		ackTimeout:     defaultAckTimeout,
+		maxUnacked:     defaultMaxUnacked,
+		origin:         newOriginChecker(OriginPolicy{}),
+		getStats:       make(chan chan hubStats),
+		logger:         defaultLogger(),
+
+		getClients: make(chan chan []*Client),
+		getClient:  make(chan *clientRequest),
+	}
+	for _, option := range options {
+		option(sh)
 	}
+	sh.origin.logger = sh.logger
+	if sh.history != nil {
+		sh.history.logger = sh.logger
+	}
+	return sh
 }
 
 func (sh *ServiceHub) run() {
@@ -78,11 +175,15 @@ func (sh *ServiceHub) run() {
 		// 断开链接
 		case client := <-sh.close:
 			delete(sh.clients, client.id)
+			sh.infoIndex.remove(client)
 			// 从uid中删除
-			delete(sh.uidClients[client.uid], client)
+			sh.removeUidClient(client.uid, client)
 			// 从group中删除
 			for group := range client.groups {
 				delete(sh.groups[group], client)
+				if len(sh.groups[group]) == 0 {
+					delete(sh.groups, group)
+				}
 			}
 		//解散组,组中每个成员的group都要去掉该租
 		case group := <-sh.disbandGroup:
@@ -117,29 +218,65 @@ func (sh *ServiceHub) run() {
 					delete(sh.groups, group)
 				}
 			}
+		// 退出所有分组
+		case client := <-sh.leaveAllGroups:
+			for group, clients := range sh.groups {
+				if _, ok := clients[client]; !ok {
+					continue
+				}
+				client.leaveGroup <- group
+				delete(clients, client)
+				if len(clients) == 0 {
+					delete(sh.groups, group)
+				}
+			}
 		// 绑定uid
 		case data := <-sh.bindUid:
 			for client, uid := range data {
 				oldUid := client.uid
-				delete(sh.uidClients[oldUid], client)
+				if oldUid == uid {
+					continue
+				}
+				sh.removeUidClient(oldUid, client)
 
-				client.uid = uid
+				client.setUid(uid)
 				if _, ok := sh.uidClients[uid]; !ok {
 					sh.uidClients[uid] = make(map[*Client]bool)
+					sh.localUidOnline(uid)
 				}
 				sh.uidClients[uid][client] = true
 			}
 		// 解绑uid
 		case client := <-sh.unbindUid:
 			uid := client.uid
-			client.uid = ""
-			if _, ok := sh.uidClients[uid]; !ok {
-				break
+			client.setUid("")
+			sh.removeUidClient(uid, client)
+		case m := <-sh.groupMessages:
+			for client := range sh.groups[m.group] {
+				client.push(m.message)
 			}
-			delete(sh.uidClients[uid], client)
-			if len(sh.uidClients[uid]) == 0 {
-				delete(sh.uidClients, uid)
+		case request := <-sh.resume:
+			request.reply <- sh.lookupResumable(request.token)
+		case reply := <-sh.getStats:
+			stats := hubStats{clients: len(sh.clients), uids: len(sh.uidClients), groups: len(sh.groups)}
+			for _, client := range sh.clients {
+				stats.queueDepth += len(client.send)
 			}
+			reply <- stats
+		case request := <-sh.getClient:
+			request.reply <- sh.clients[request.clientId]
+		case reply := <-sh.getClients:
+			clients := make([]*Client, 0, len(sh.clients))
+			for _, client := range sh.clients {
+				clients = append(clients, client)
+			}
+			reply <- clients
+		case reply := <-sh.getUids:
+			uids := make([]string, 0, len(sh.uidClients))
+			for uid := range sh.uidClients {
+				uids = append(uids, uid)
+			}
+			reply <- uids
 		case service := <-sh.addServices:
 			for addr, client := range service {
 				sh.otherServices[addr] = client
@@ -150,30 +287,61 @@ func (sh *ServiceHub) run() {
 	}
 }
 
+// 在run以外按clientId查找client，不存在时返回nil
+func (sh *ServiceHub) findClient(clientId string) *Client {
+	reply := make(chan *Client, 1)
+	sh.getClient <- &clientRequest{clientId: clientId, reply: reply}
+	return <-reply
+}
+
+// 从uid中删除client，uid无client时删除该uid
+func (sh *ServiceHub) removeUidClient(uid string, client *Client) {
+	if _, ok := sh.uidClients[uid]; !ok {
+		return
+	}
+	delete(sh.uidClients[uid], client)
+	if len(sh.uidClients[uid]) == 0 {
+		delete(sh.uidClients, uid)
+		sh.localUidOffline(uid)
+	}
+}
+
 func (sh *ServiceHub) Start(addr string) {
 	go sh.run()
 	go sh.checkRegisterConnection()
 	go sh.StartRpc()
+	go sh.dispatchPresence()
 
 	Api = &ServiceApi{hub: sh}
-	log.Println("starting Service...")
+	sh.log(LogInfo, "starting service", F("addr", addr), F("rpc_addr", sh.rpcAddr()))
 
+	if sh.metrics != nil {
+		http.HandleFunc(sh.metrics.path, sh.serveMetrics)
+	}
+	http.HandleFunc("/healthz", sh.serveHealthz)
+	http.HandleFunc("/readyz", sh.serveReadyz)
+	http.HandleFunc("/debug/sessions", sh.serveDebugSessions)
 	http.HandleFunc("/", func(writer http.ResponseWriter, request *http.Request) {
 		ServeWs(sh, writer, request)
 	})
 
 	err := http.ListenAndServe(addr, nil)
 	if err != nil {
-		log.Fatal("ListenAndServe: ", err)
+		fatal(sh.logger, "service listen error", err)
 	}
 }
 
+// 本服务的rpc地址
+func (sh *ServiceHub) rpcAddr() string {
+	return sh.lanIp + ":" + strconv.FormatUint(uint64(sh.rpcPort), 10)
+}
+
 // 开启rpc服务
 func (sh *ServiceHub) StartRpc() {
 	listen, err := net.Listen("tcp", ":"+strconv.FormatUint(uint64(sh.rpcPort), 10))
 
 	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+		fatal(sh.logger, "rpc listen error", err)
 	}
 	rm := rpcMethods{hub: sh}
 	sh.rm = &rm
@@ -186,16 +354,18 @@ func (sh *ServiceHub) StartRpc() {
 		Timeout: 5 * time.Second,
 	}
 
-	s := grpc.NewServer(grpc.KeepaliveEnforcementPolicy(enforcementPolicy), grpc.KeepaliveParams(serverParameters))
+	s := grpc.NewServer(grpc.KeepaliveEnforcementPolicy(enforcementPolicy), grpc.KeepaliveParams(serverParameters), grpc.UnaryInterceptor(sh.unaryInterceptor))
 	pb.RegisterServiceApiServer(s, sh.rm)
-	log.Println("rpc服务已经开启")
+	sh.log(LogInfo, "rpc服务已经开启", F("port", sh.rpcPort))
+	atomic.StoreInt32(&sh.rpcServing, 1)
+	defer atomic.StoreInt32(&sh.rpcServing, 0)
 	s.Serve(listen)
 }
 
 //链接到register
 func (sh *ServiceHub) connectToRegister() error {
 	u := url.URL{Scheme: "ws", Host: sh.registerAddr, Path: "/"}
-	log.Printf("connecting to %s", u.String())
+	sh.log(LogInfo, "connecting to register", F("url", u.String()))
 
 	//创建到register的链接
 	c, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
@@ -212,29 +382,37 @@ func (sh *ServiceHub) connectToRegister() error {
 		for {
 			_, data, err := c.ReadMessage()
 			if err != nil {
-				log.Println("read:", err)
+				sh.log(LogWarn, "read from register error", F("error", err))
 				return
 			}
 			var message RegisterMessage
 			err = json.Unmarshal(data, &message)
 			if err != nil {
-				log.Println("message err:", string(data[:]))
+				sh.log(LogError, "invalid register message", F("data", string(data)))
 			}
-			log.Println("read message from register:", message)
+			sh.log(LogDebug, "read message from register", F("action", message.Action))
 			switch message.Action {
 			case registerActionBroadcastAddr:
 				sh.otherAddress = map[string]bool{}
 				for _, addr := range message.Addresses {
 					sh.otherAddress[addr] = true
 				}
-				log.Println(sh.otherAddress)
+				sh.log(LogDebug, "services updated", F("addresses", message.Addresses))
+			case registerActionBroadcastOnline:
+				for _, uid := range message.Uids {
+					sh.presenceEvents <- PresenceEvent{Uid: uid, Status: PresenceOnline}
+				}
+			case registerActionBroadcastOffline:
+				for _, uid := range message.Uids {
+					sh.presenceEvents <- PresenceEvent{Uid: uid, Status: PresenceOffline}
+				}
 			}
 		}
 	}()
 
 	message := RegisterMessage{
 		Action:  registerActionConnect,
-		RpcAddr: sh.lanIp + ":" + strconv.FormatUint(uint64(sh.rpcPort), 10),
+		RpcAddr: sh.rpcAddr(),
 	}
 
 	// 发送注册信息给register
@@ -243,17 +421,44 @@ func (sh *ServiceHub) connectToRegister() error {
 		return err
 	}
 
+	// 丢弃断线期间积压的uid变化，然后同步本地全部uid
+	if err = sh.resyncUids(c); err != nil {
+		return err
+	}
+	atomic.StoreInt32(&sh.registerConnected, 1)
+	defer atomic.StoreInt32(&sh.registerConnected, 0)
+
 	//保持链接
 	for {
 		select {
 		// 如果read通道关闭，则结束链接
 		case <-done:
-			log.Println("done")
 			return errors.New("done")
+		case message := <-sh.registerSend:
+			err = c.WriteJSON(message)
+			if err != nil {
+				return err
+			}
+		case <-sh.registerResync:
+			if err = sh.resyncUids(c); err != nil {
+				return err
+			}
 		}
 	}
 }
 
+// 丢弃积压的uid变化，然后同步本地全部uid
+func (sh *ServiceHub) resyncUids(c *websocket.Conn) error {
+	for len(sh.registerSend) > 0 {
+		<-sh.registerSend
+	}
+	select {
+	case <-sh.registerResync:
+	default:
+	}
+	return c.WriteJSON(&RegisterMessage{Action: registerActionSyncUids, Uids: sh.localUids()})
+}
+
 //保持和register的链接，断开连接后自动重连
 func (sh *ServiceHub) checkRegisterConnection() {
 	for {
@@ -1,8 +1,8 @@
 package websocket
 
 import (
+	pb "github.com/bin-x/websocket/proto"
 	"github.com/gorilla/websocket"
-	"log"
 	"net/http"
 	"sync/atomic"
 	"time"
@@ -12,21 +12,57 @@ var currentId uint32 = 0
 
 // Client is a middleman between the websocket connection and the hub.
 type Client struct {
-	id     string
-	uid    string
-	groups map[string]bool
-	info   map[string]string
+	id  string
+	uid string
+	// uid的副本，供read等其他goroutine读取
+	sharedUid atomic.Value
+	groups    map[string]bool
+	info      map[string]string
+	// info每次修改后加1
+	infoVersion uint64
+
+	connectTime time.Time
+	remoteAddr  string
 
 	hub *ServiceHub
-	// The websocket connection.
+	// The websocket connection. 断线等待恢复时为nil
 	conn *websocket.Conn
+	// 当前连接关闭时close
+	connDone chan struct{}
+	// 当前连接的write退出时close
+	writerDone chan struct{}
 	// Buffered channel of outbound messages.
 	send       chan []byte
 	joinGroup  chan string
 	leaveGroup chan string
-	setInfo    chan map[string]string
-	updateInfo chan map[string]string
-	done       chan bool
+	infoOps    chan *infoOp
+	done       chan CloseEvent
+	// 由run生成会话信息
+	getSession chan chan *pb.Client
+
+	disconnect chan *disconnectEvent
+	resume     chan *websocket.Conn
+	// 会话结束时close
+	closed chan struct{}
+	// 用于断线后恢复会话，未开启时为空
+	resumeToken string
+	// 可靠发送未确认的消息
+	outbox outbox
+	// 连接时的ip，用于释放连接数限制
+	admitIp string
+
+	// 最后一次收到（或发送）消息的时间，UnixNano
+	lastActive int64
+	closeEvent CloseEvent
+	// 已因发送队列已满请求关闭，1为是
+	slowClosing int32
+	// 未通知OnConnect就关闭，不调用OnClose，1为是
+	aborted int32
+}
+
+type disconnectEvent struct {
+	conn  *websocket.Conn
+	event CloseEvent
 }
 
 func NewServiceClient(hub *ServiceHub, conn *websocket.Conn) *Client {
@@ -38,76 +74,157 @@ func NewServiceClient(hub *ServiceHub, conn *websocket.Conn) *Client {
 		send:       make(chan []byte, 256),
 		joinGroup:  make(chan string),
 		leaveGroup: make(chan string),
-		setInfo:    make(chan map[string]string),
-		updateInfo: make(chan map[string]string),
-		done:       make(chan bool),
+		infoOps:    make(chan *infoOp),
+		done:       make(chan CloseEvent),
+		getSession: make(chan chan *pb.Client),
+		disconnect: make(chan *disconnectEvent),
+		resume:     make(chan *websocket.Conn),
+		closed:     make(chan struct{}),
+
+		connectTime: time.Now(),
+		remoteAddr:  conn.RemoteAddr().String(),
 	}
 	client.generateId()
+	if hub.resumeGrace > 0 {
+		client.resumeToken = newResumeToken(client.id)
+	}
 	return client
 }
 
+// 修改uid，只在hub的run中调用
+func (c *Client) setUid(uid string) {
+	c.uid = uid
+	c.sharedUid.Store(uid)
+}
+
+// 在hub的run以外读取uid
+func (c *Client) loadUid() string {
+	uid, _ := c.sharedUid.Load().(string)
+	return uid
+}
+
+// 在run以外获取会话信息，会话已结束时返回nil。
+// 不能在hub的run中调用，client的run退出时会等待hub的run。
+func (c *Client) snapshot() *pb.Client {
+	reply := make(chan *pb.Client, 1)
+	select {
+	case c.getSession <- reply:
+		return <-reply
+	case <-c.closed:
+		return nil
+	}
+}
+
+// 完整的会话信息，只在run中调用
+func (c *Client) session() *pb.Client {
+	groups := make([]string, 0, len(c.groups))
+	for group := range c.groups {
+		groups = append(groups, group)
+	}
+	info := make(map[string]string, len(c.info))
+	for k, v := range c.info {
+		info[k] = v
+	}
+	return &pb.Client{
+		Id:          c.id,
+		Uid:         c.uid,
+		Group:       groups,
+		Info:        info,
+		InfoVersion: c.infoVersion,
+		Node:        c.hub.rpcAddr(),
+		ConnectTime: c.connectTime.Unix(),
+		RemoteAddr:  c.remoteAddr,
+	}
+}
+
 func (c *Client) generateId() {
 	// 高并发时防止出现相同id
 	id := atomic.AddUint32(&currentId, 1)
 	c.id = AddressToClientId(c.hub.lanIp, c.hub.rpcPort, id)
 }
 
-func (c *Client) read() {
+func (c *Client) read(conn *websocket.Conn, done chan struct{}) {
 	defer func() {
 		if err := recover(); err != nil {
-			log.Println(err)
+			c.hub.log(LogError, "panic in client read", F("client_id", c.id), F("error", err))
 		}
-		log.Println("recover on read...")
-	}()
-	defer func() {
-		c.done <- true
 	}()
-	c.conn.SetReadLimit(maxMessageSize)
-	c.conn.SetReadDeadline(time.Now().Add(pongWait))
-	c.conn.SetPongHandler(func(string) error { c.conn.SetReadDeadline(time.Now().Add(pongWait)); return nil })
+	event := CloseEvent{Code: websocket.CloseAbnormalClosure, Initiator: CloseByPeer}
+	defer func() { c.disconnected(conn, event) }()
+	conn.SetReadLimit(maxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error { conn.SetReadDeadline(time.Now().Add(pongWait)); return nil })
 	for {
-		_, message, err := c.conn.ReadMessage()
+		_, message, err := conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("error: %v", err)
+				c.hub.log(LogDebug, "client read error", F("client_id", c.id), F("error", err))
 			}
+			c.connError(readErrorKind(err), err, done)
+			event = closeEventFromError(err)
+			break
+		}
+		c.touch()
+		c.hub.metrics.messageReceived(len(message))
+		allow, closeEvent := c.checkRateLimit(conn, message)
+		if closeEvent != nil {
+			c.requestClose(*closeEvent)
+			break
+		}
+		if !allow {
+			continue
+		}
+		if c.handleAck(message) || c.hub.handleResponse(c.id, message) {
+			continue
+		}
+		keep := true
+		if !c.protect(func() { keep = c.handleMessage(message) }) {
+			c.requestClose(closeInternalError)
+			break
+		}
+		if !keep {
 			break
 		}
-		c.hub.application.OnMessage(c.id, message)
 	}
 }
 
-func (c *Client) write() {
+// done关闭表示该连接已被替换或会话已结束，退出时close exited
+func (c *Client) write(conn *websocket.Conn, done chan struct{}, exited chan struct{}) {
+	defer close(exited)
 	defer func() {
 		if err := recover(); err != nil {
-			log.Println(err)
-
+			c.hub.log(LogError, "panic in client write", F("client_id", c.id), F("error", err))
 		}
-		log.Println("recover on write...")
 	}()
 	ticker := time.NewTicker(pingPeriod)
-	defer func() {
-		ticker.Stop()
-		c.done <- true
-	}()
+	defer ticker.Stop()
 	for {
 		select {
-		case message, ok := <-c.send:
+		case <-done:
+			return
+		default:
+		}
+		select {
+		case <-done:
+			return
+		case message := <-c.send:
 			//log.Println("sending message: ", message)
-			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
-			if !ok {
-				// The hub closed the channel.
-				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
-				return
-			}
-
-			err := c.conn.WriteMessage(websocket.TextMessage, message)
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			err := conn.WriteMessage(websocket.TextMessage, message)
 			if err != nil {
+				c.connError(ErrorKindWrite, err, done)
+				c.disconnected(conn, closeEventFromError(err))
 				return
 			}
+			c.hub.metrics.messageSent(len(message))
+			if c.hub.idleResetOnSend {
+				c.touch()
+			}
 		case <-ticker.C:
-			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
-			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.connError(ErrorKindWrite, err, done)
+				c.disconnected(conn, closeEventFromError(err))
 				return
 			}
 
@@ -115,72 +232,293 @@ func (c *Client) write() {
 	}
 }
 
+// 将消息放入发送队列，队列已满或会话已结束时丢弃
+func (c *Client) push(message []byte) bool {
+	select {
+	case <-c.closed:
+		return false
+	default:
+	}
+	select {
+	case c.send <- message:
+		return true
+	default:
+		c.hub.metrics.messageDropped()
+		if c.hub.closeSlowConsumers {
+			c.closeSlow()
+			return false
+		}
+		c.hub.log(LogWarn, "send queue full, drop message", F("client_id", c.id))
+		return false
+	}
+}
+
+// 连接断开时通知run
+func (c *Client) disconnected(conn *websocket.Conn, event CloseEvent) {
+	select {
+	case c.disconnect <- &disconnectEvent{conn: conn, event: event}:
+	case <-c.closed:
+	}
+}
+
+// 主动关闭会话
+func (c *Client) requestClose(event CloseEvent) {
+	select {
+	case c.done <- event:
+	case <-c.closed:
+	}
+}
+
+// 使用新的连接恢复会话，会话已结束时返回false
+func (c *Client) resumeWith(conn *websocket.Conn) bool {
+	select {
+	case c.resume <- conn:
+		return true
+	case <-c.closed:
+		return false
+	}
+}
+
+// 开始使用conn收发消息，只在run中调用
+func (c *Client) attach(conn *websocket.Conn, resumed bool) {
+	c.conn = conn
+	c.connDone = make(chan struct{})
+	c.writerDone = make(chan struct{})
+	c.remoteAddr = conn.RemoteAddr().String()
+	c.touch()
+
+	// 首先发送会话信息，断线期间积压的消息随后由write发送
+	if c.resumeToken != "" {
+		message, _ := MarshalEnvelope(EnvelopeTypeSession, "", SessionData{ClientId: c.id, ResumeToken: c.resumeToken, Resumed: resumed})
+		conn.SetWriteDeadline(time.Now().Add(writeWait))
+		conn.WriteMessage(websocket.TextMessage, message)
+	}
+
+	go c.write(conn, c.connDone, c.writerDone)
+	go c.read(conn, c.connDone)
+	if resumed {
+		c.retransmit(true)
+	}
+}
+
+// 关闭当前连接，只在run中调用
+func (c *Client) detach() {
+	if c.conn == nil {
+		return
+	}
+	c.conn.Close()
+	if c.connDone != nil {
+		close(c.connDone)
+	}
+	c.conn = nil
+	c.connDone = nil
+	c.writerDone = nil
+}
+
 func (c *Client) run() {
 	defer func() {
 		if err := recover(); err != nil {
-			log.Println(err)
+			c.hub.log(LogError, "panic in client run", F("client_id", c.id), F("error", err))
 		}
-		log.Println("recover on client run ...")
 	}()
 	defer c.close()
-	defer c.hub.application.OnClose(c.id)
+	defer c.onClose()
+
+	// 断线后等待恢复会话
+	var grace *time.Timer
+	var graceC <-chan time.Time
+	stopGrace := func() {
+		if grace != nil {
+			grace.Stop()
+			grace, graceC = nil, nil
+		}
+	}
+	defer stopGrace()
 
+	// 定时重发超时未确认的消息
+	var retryC <-chan time.Time
+	if c.hub.ackTimeout > 0 {
+		retry := time.NewTicker(c.hub.ackTimeout)
+		defer retry.Stop()
+		retryC = retry.C
+	}
+
+	// 空闲超时和最长时间
+	var idle *time.Timer
+	var idleC, lifetimeC <-chan time.Time
+	if c.hub.idleTimeout > 0 {
+		idle = time.NewTimer(c.hub.idleTimeout)
+		defer idle.Stop()
+		idleC = idle.C
+	}
+	if c.hub.maxLifetime > 0 {
+		lifetime := time.NewTimer(c.hub.maxLifetime - time.Since(c.connectTime))
+		defer lifetime.Stop()
+		lifetimeC = lifetime.C
+	}
+
+	if c.conn != nil {
+		c.attach(c.conn, false)
+	}
 	for {
 		select {
 		case group := <-c.joinGroup:
 			c.groups[group] = true
 		case group := <-c.leaveGroup:
 			delete(c.groups, group)
-		case info := <-c.setInfo:
-			c.info = info
-		case infos := <-c.updateInfo:
-			for k, v := range infos {
-				c.info[k] = v
+		case op := <-c.infoOps:
+			op.reply <- c.applyInfoOp(op)
+		case reply := <-c.getSession:
+			reply <- c.session()
+		case d := <-c.disconnect:
+			// 已被替换的连接
+			if d.conn != c.conn {
+				break
 			}
-		case <-c.done:
+			// 连接已断开，不再发送close frame
+			c.closeEvent = d.event
+			c.detach()
+			if c.hub.resumeGrace <= 0 {
+				return
+			}
+			grace = time.NewTimer(c.hub.resumeGrace)
+			graceC = grace.C
+		case conn := <-c.resume:
+			stopGrace()
+			c.detach()
+			c.attach(conn, true)
+		case <-retryC:
+			if c.conn != nil {
+				c.retransmit(false)
+			}
+		case <-idleC:
+			remaining := c.idleRemaining(time.Now())
+			// 等待恢复会话时不检查空闲
+			if c.conn == nil || remaining > 0 {
+				if remaining <= 0 {
+					remaining = c.hub.idleTimeout
+				}
+				idle.Reset(remaining)
+				break
+			}
+			c.closeEvent = CloseEvent{Code: CloseIdleTimeout, Reason: "idle timeout", Initiator: CloseByTimeout}
+			return
+		case <-lifetimeC:
+			c.closeEvent = CloseEvent{Code: CloseLifetimeExceeded, Reason: "session lifetime exceeded", Initiator: CloseByTimeout}
+			return
+		case <-graceC:
+			return
+		case event := <-c.done:
+			c.closeEvent = event
 			return
 		}
 	}
 }
 func (c *Client) close() {
 	c.hub.close <- c
-	c.conn.Close()
-	close(c.done)
-	close(c.send)
+	close(c.closed)
+	if c.conn != nil {
+		// 慢消费者的队列已满，再发送只会等到超时
+		if c.closeEvent.Code != CloseSlowConsumer {
+			c.flush()
+		}
+		c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(c.closeEvent.Code, closeReason(c.closeEvent.Reason)), time.Now().Add(writeWait))
+	}
+	c.detach()
+	c.hub.admission.release(c.admitIp)
 	close(c.joinGroup)
 	close(c.leaveGroup)
-	close(c.setInfo)
-	close(c.updateInfo)
+	// infoOps不关闭，applyInfoOp通过closed得知client已关闭
 }
 
 // serveWs handles websocket requests from the peer.
 func ServeWs(hub *ServiceHub, w http.ResponseWriter, r *http.Request) {
+	// 先检查来源，被拒绝的请求不占用连接名额，也不会踢掉已有的连接
+	if !hub.origin.check(r) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
 
 	var upgrader = websocket.Upgrader{
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
-		CheckOrigin: func(r *http.Request) bool {
-			return true
-		},
+		// 已在升级前检查
+		CheckOrigin: func(r *http.Request) bool { return true },
+	}
+	// 使用resume_token恢复之前的会话，不占用新的连接名额
+	var resumable *Client
+	if token := r.URL.Query().Get("resume_token"); token != "" && hub.resumeGrace > 0 {
+		resumable = hub.findResumable(token)
+	}
+
+	ip := requestIp(r)
+	var uid string
+	// 检查uid限制到绑定完成期间，同一uid的其他连接需要等待
+	unlockUid := func() {}
+	if resumable == nil {
+		if status := hub.admission.acquire(ip); status != 0 {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		if hub.admission != nil && hub.admission.limits.UidFromRequest != nil {
+			uid = hub.admission.limits.UidFromRequest(r)
+			if uid != "" {
+				unlockUid = hub.admission.lockUid(uid)
+				if Api.admitUid("", uid) != nil {
+					unlockUid()
+					hub.admission.release(ip)
+					http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+					return
+				}
+			}
+		}
 	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Println(err)
+		hub.log(LogDebug, "upgrade error", F("remote_addr", r.RemoteAddr), F("error", err))
+		if resumable == nil {
+			unlockUid()
+			hub.admission.release(ip)
+		}
 		return
 	}
 
+	if resumable != nil {
+		if resumable.resumeWith(conn) {
+			hub.log(LogInfo, "client resumed", F("client_id", resumable.id), F("remote_addr", conn.RemoteAddr().String()))
+			return
+		}
+		// 会话已结束，作为新连接处理
+		if status := hub.admission.acquire(ip); status != 0 {
+			conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, http.StatusText(status)), time.Now().Add(writeWait))
+			conn.Close()
+			return
+		}
+	}
+
 	// todo, 初始化client id
 	client := NewServiceClient(hub, conn)
+	client.admitIp = ip
 	hub.connect <- client
 
-	log.Println("all client:", hub.clients)
-
 	// Allow collection of memory referenced by the caller by doing all work in
 	// new goroutines.
 	go client.run()
-	go client.write()
-	go client.read()
 
-	hub.application.OnConnect(client.id)
-	log.Println("new client from " + conn.RemoteAddr().String())
+	if uid != "" {
+		err := Api.bindUid(client.id, uid)
+		unlockUid()
+		if err != nil {
+			// 检查后其他服务上又有该uid的连接
+			hub.log(LogWarn, "bind uid error", F("client_id", client.id), F("uid", uid), F("error", err))
+			client.abort(CloseEvent{Code: websocket.ClosePolicyViolation, Reason: "bind uid failed", Initiator: CloseByServer})
+			return
+		}
+	}
+	if !client.protect(func() { hub.application.OnConnect(client.id) }) {
+		client.requestClose(closeInternalError)
+		return
+	}
+	hub.log(LogDebug, "new client", F("client_id", client.id), F("remote_addr", conn.RemoteAddr().String()))
 }
@@ -0,0 +1,16 @@
+package websocket
+
+// 创建ServiceHub时的可选配置
+type ServiceOption func(sh *ServiceHub)
+
+// 为info中的这些key建立本地索引，加快按info查询client的速度
+func WithInfoIndex(keys ...string) ServiceOption {
+	return func(sh *ServiceHub) {
+		if sh.infoIndex == nil {
+			sh.infoIndex = newInfoIndex()
+		}
+		for _, key := range keys {
+			sh.infoIndex.keys[key] = true
+		}
+	}
+}
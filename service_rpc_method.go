@@ -1,8 +1,11 @@
 package websocket
 
 import (
+	"encoding/json"
+	"errors"
 	pb "github.com/bin-x/websocket/proto"
 	"golang.org/x/net/context"
+	"time"
 )
 
 type rpcMethods struct {
@@ -71,6 +74,68 @@ func (rm *rpcMethods) GetInfo(ctx context.Context, request *pb.ServiceRequest) (
 	return &pb.ServiceResponse{Clients: clients}, nil
 }
 
+func (rm *rpcMethods) GetClientSession(ctx context.Context, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
+	var clients []*pb.Client
+	if c, ok := rm.hub.clients[request.ClientId]; ok {
+		clients = appendSession(clients, c)
+	}
+	return &pb.ServiceResponse{Clients: clients}, nil
+}
+
+func (rm *rpcMethods) GetSessionsByGroup(ctx context.Context, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
+	clients := make([]*pb.Client, 0, len(rm.hub.groups[request.Group]))
+	for c := range rm.hub.groups[request.Group] {
+		clients = appendSession(clients, c)
+	}
+	return &pb.ServiceResponse{Clients: clients}, nil
+}
+
+func (rm *rpcMethods) GetSessionsByUid(ctx context.Context, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
+	clients := make([]*pb.Client, 0, len(rm.hub.uidClients[request.Uid]))
+	for c := range rm.hub.uidClients[request.Uid] {
+		clients = appendSession(clients, c)
+	}
+	return &pb.ServiceResponse{Clients: clients}, nil
+}
+
+func (rm *rpcMethods) GetAllSessions(ctx context.Context, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
+	clients := make([]*pb.Client, 0, len(rm.hub.clients))
+	for _, c := range rm.hub.clients {
+		clients = appendSession(clients, c)
+	}
+	return &pb.ServiceResponse{Clients: clients}, nil
+}
+
+// 由client的run生成会话信息，跳过已结束的会话
+func appendSession(clients []*pb.Client, c *Client) []*pb.Client {
+	if session := c.snapshot(); session != nil {
+		clients = append(clients, session)
+	}
+	return clients
+}
+
+func (rm *rpcMethods) QueryClients(ctx context.Context, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
+	var clientIds []string
+	count := 0
+	for client := range rm.hub.queryCandidates(request.Predicates) {
+		matched := true
+		for _, predicate := range request.Predicates {
+			if !client.match(predicate) {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		count++
+		if !request.CountOnly {
+			clientIds = append(clientIds, client.id)
+		}
+	}
+	return &pb.ServiceResponse{Count: int32(count), ClientIds: clientIds}, nil
+}
+
 func (rm *rpcMethods) GetClientCountByGroup(ctx context.Context, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
 	count := len(rm.hub.groups[request.Group])
 	return &pb.ServiceResponse{Count: int32(count)}, nil
@@ -89,25 +154,112 @@ func (rm *rpcMethods) GetClientCountByGroup(ctx context.Context, request *pb.Ser
 //}
 
 func (rm *rpcMethods) SendToClient(ctx context.Context, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
-	if client, ok := rm.hub.clients[request.ClientId]; ok {
-		client.send <- request.Message
+	client, ok := rm.hub.clients[request.ClientId]
+	if ok {
+		ok = client.deliver(request.Message, request.Reliable)
 	}
-	return &pb.ServiceResponse{}, nil
+	return &pb.ServiceResponse{Success: ok}, nil
+}
+
+// 记录请求的client和发起请求的服务后发送，回复只接受该client的并转发给该服务
+func (rm *rpcMethods) SendRequest(ctx context.Context, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
+	origin := callerOf(ctx)
+	if origin == "" {
+		return nil, errors.New("unknown caller of SendRequest")
+	}
+	client, ok := rm.hub.clients[request.ClientId]
+	if !ok {
+		return &pb.ServiceResponse{Success: false}, nil
+	}
+	timeout := requestClientTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	rm.hub.requestRoutes.add(request.RequestId, requestRoute{clientId: client.id, origin: origin}, timeout)
+	if !client.deliver(request.Message, false) {
+		rm.hub.requestRoutes.remove(request.RequestId)
+		return &pb.ServiceResponse{Success: false}, nil
+	}
+	return &pb.ServiceResponse{Success: true}, nil
+}
+
+func (rm *rpcMethods) DeliverResponse(ctx context.Context, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
+	var envelope Envelope
+	if err := json.Unmarshal(request.Message, &envelope); err != nil {
+		return nil, err
+	}
+	ok := rm.hub.requests.resolve(request.RequestId, request.ClientId, &envelope)
+	return &pb.ServiceResponse{Success: ok}, nil
 }
 
 func (rm *rpcMethods) SendToUid(ctx context.Context, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
-	if clients, ok := rm.hub.uidClients[request.Uid]; ok {
-		for client := range clients {
-			client.send <- request.Message
+	excluded := excludeFilter(request)
+	count := 0
+	clients, ok := rm.hub.uidClients[request.Uid]
+	for client := range clients {
+		if excluded(client) {
+			continue
 		}
+		client.deliver(request.Message, request.Reliable)
+		count++
 	}
-	return &pb.ServiceResponse{}, nil
+	return &pb.ServiceResponse{Result: ok, Count: int32(count)}, nil
 }
 
 func (rm *rpcMethods) SendToGroup(ctx context.Context, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
+	rm.hub.history.record(request.Group, request.Message)
+	excluded := excludeFilter(request)
 	if clients, ok := rm.hub.groups[request.Group]; ok {
 		for client := range clients {
-			client.send <- request.Message
+			if excluded(client) {
+				continue
+			}
+			client.deliver(request.Message, request.Reliable)
+		}
+	}
+	return &pb.ServiceResponse{}, nil
+}
+
+func (rm *rpcMethods) SendToClients(ctx context.Context, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
+	excluded := excludeFilter(request)
+	sent := make(map[*Client]bool)
+	for _, clientId := range request.ClientIds {
+		if client, ok := rm.hub.clients[clientId]; ok && !sent[client] && !excluded(client) {
+			sent[client] = true
+			client.deliver(request.Message, request.Reliable)
+		}
+	}
+	return &pb.ServiceResponse{}, nil
+}
+
+func (rm *rpcMethods) SendToUids(ctx context.Context, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
+	excluded := excludeFilter(request)
+	sent := make(map[*Client]bool)
+	for _, uid := range request.Uids {
+		for client := range rm.hub.uidClients[uid] {
+			if sent[client] || excluded(client) {
+				continue
+			}
+			sent[client] = true
+			client.deliver(request.Message, request.Reliable)
+		}
+	}
+	return &pb.ServiceResponse{}, nil
+}
+
+func (rm *rpcMethods) SendToGroups(ctx context.Context, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
+	for _, group := range request.Groups {
+		rm.hub.history.record(group, request.Message)
+	}
+	excluded := excludeFilter(request)
+	sent := make(map[*Client]bool)
+	for _, group := range request.Groups {
+		for client := range rm.hub.groups[group] {
+			if sent[client] || excluded(client) {
+				continue
+			}
+			sent[client] = true
+			client.deliver(request.Message, request.Reliable)
 		}
 	}
 	return &pb.ServiceResponse{}, nil
@@ -119,9 +271,29 @@ func (rm *rpcMethods) BindUid(ctx context.Context, request *pb.ServiceRequest) (
 		data[client] = request.Uid
 		rm.hub.bindUid <- data
 	}
+	// 每个服务都发送自己保存的离线消息
+	if rm.hub.messageStore != nil {
+		go rm.hub.flushOfflineMessages(request.Uid, request.ClientId)
+	}
 	return &pb.ServiceResponse{}, nil
 }
 
+func (rm *rpcMethods) GetOfflineMessageStats(ctx context.Context, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
+	if rm.hub.messageStore == nil {
+		return &pb.ServiceResponse{}, nil
+	}
+	stats, err := rm.hub.messageStore.Stats(request.Uid)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ServiceResponse{StoreStats: &pb.StoreStats{
+		Pending:   stats.Pending,
+		Delivered: stats.Delivered,
+		Expired:   stats.Expired,
+		Dropped:   stats.Dropped,
+	}}, nil
+}
+
 func (rm *rpcMethods) UnbindUid(ctx context.Context, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
 	if client, ok := rm.hub.clients[request.ClientId]; ok {
 		rm.hub.unbindUid <- client
@@ -139,6 +311,12 @@ func (rm *rpcMethods) JoinGroup(ctx context.Context, request *pb.ServiceRequest)
 		data := make(map[*Client]string)
 		data[client] = request.Group
 		rm.hub.joinGroup <- data
+		// 发送历史消息
+		if request.Replay != nil {
+			for _, message := range rm.hub.history.query(request.Group, request.Replay) {
+				client.push(message.Message)
+			}
+		}
 	}
 	return &pb.ServiceResponse{}, nil
 }
@@ -150,9 +328,63 @@ func (rm *rpcMethods) LeaveGroup(ctx context.Context, request *pb.ServiceRequest
 	return &pb.ServiceResponse{}, nil
 }
 
+func (rm *rpcMethods) JoinGroupByUid(ctx context.Context, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
+	if clients, ok := rm.hub.uidClients[request.Uid]; ok {
+		data := make(map[*Client]string, len(clients))
+		for client := range clients {
+			data[client] = request.Group
+		}
+		rm.hub.joinGroup <- data
+	}
+	return &pb.ServiceResponse{}, nil
+}
+
+func (rm *rpcMethods) LeaveAllGroups(ctx context.Context, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
+	if client, ok := rm.hub.clients[request.ClientId]; ok {
+		rm.hub.leaveAllGroups <- client
+	}
+	return &pb.ServiceResponse{}, nil
+}
+
+func (rm *rpcMethods) DisbandGroup(ctx context.Context, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
+	rm.hub.disbandGroup <- request.Group
+	rm.hub.history.remove(request.Group)
+	return &pb.ServiceResponse{}, nil
+}
+
+func (rm *rpcMethods) GetGroupsByClientId(ctx context.Context, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
+	var groups []string
+	if client, ok := rm.hub.clients[request.ClientId]; ok {
+		groups = make([]string, 0, len(client.groups))
+		for group := range client.groups {
+			groups = append(groups, group)
+		}
+	}
+	return &pb.ServiceResponse{Groups: groups}, nil
+}
+
+func (rm *rpcMethods) GetGroupsByUid(ctx context.Context, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
+	groupMaps := make(map[string]bool)
+	if clients, ok := rm.hub.uidClients[request.Uid]; ok {
+		for client := range clients {
+			for group := range client.groups {
+				groupMaps[group] = true
+			}
+		}
+	}
+	groups := make([]string, 0, len(groupMaps))
+	for group := range groupMaps {
+		groups = append(groups, group)
+	}
+	return &pb.ServiceResponse{Groups: groups}, nil
+}
+
 func (rm *rpcMethods) CloseClient(ctx context.Context, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
 	if client, ok := rm.hub.clients[request.ClientId]; ok {
-		client.done <- true
+		if len(request.Message) > 0 {
+			client.push(request.Message)
+		}
+		client.requestClose(CloseEvent{Code: closeCode(int(request.CloseCode)), Reason: request.CloseReason, Initiator: CloseByServer})
 	}
 	return &pb.ServiceResponse{}, nil
 }
@@ -163,16 +395,41 @@ func (rm *rpcMethods) IsOnline(ctx context.Context, request *pb.ServiceRequest)
 }
 
 func (rm *rpcMethods) UpdateInfo(ctx context.Context, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
-	if client, ok := rm.hub.clients[request.ClientId]; ok {
-		client.updateInfo <- request.Info
+	return rm.applyInfoOp(request, &infoOp{action: infoActionUpdate, info: request.Info})
+}
+
+func (rm *rpcMethods) CompareAndSetInfo(ctx context.Context, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
+	return rm.applyInfoOp(request, &infoOp{action: infoActionCompareAndSet, info: request.Info, version: request.InfoVersion})
+}
+
+func (rm *rpcMethods) DeleteInfoKeys(ctx context.Context, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
+	return rm.applyInfoOp(request, &infoOp{action: infoActionDeleteKeys, keys: request.InfoKeys})
+}
+
+// 交给client的run修改info，并返回修改后的版本号。client不存在或已关闭时Success为false
+func (rm *rpcMethods) applyInfoOp(request *pb.ServiceRequest, op *infoOp) (*pb.ServiceResponse, error) {
+	client := rm.hub.findClient(request.ClientId)
+	if client == nil {
+		return &pb.ServiceResponse{}, nil
 	}
-	return &pb.ServiceResponse{Success: true}, nil
+	op.reply = make(chan infoResult, 1)
+	select {
+	case client.infoOps <- op:
+	case <-client.closed:
+		return &pb.ServiceResponse{}, nil
+	}
+	result := <-op.reply
+	return &pb.ServiceResponse{Success: !result.conflict, Conflict: result.conflict, InfoVersion: result.version}, nil
 }
 
 func (rm *rpcMethods) SendToAll(ctx context.Context, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
 
+	excluded := excludeFilter(request)
 	for _, client := range rm.hub.clients {
-		client.send <- request.Message
+		if excluded(client) {
+			continue
+		}
+		client.deliver(request.Message, request.Reliable)
 	}
 
 	return &pb.ServiceResponse{Success: true}, nil
@@ -192,8 +449,5 @@ func (rm *rpcMethods) GetAllUid(ctx context.Context, request *pb.ServiceRequest)
 }
 
 func (rm *rpcMethods) SetInfo(ctx context.Context, request *pb.ServiceRequest) (*pb.ServiceResponse, error) {
-	if client, ok := rm.hub.clients[request.ClientId]; ok {
-		client.setInfo <- request.Info
-	}
-	return &pb.ServiceResponse{Success: true}, nil
+	return rm.applyInfoOp(request, &infoOp{action: infoActionSet, info: request.Info})
 }
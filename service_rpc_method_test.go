@@ -3,8 +3,10 @@ package websocket
 import (
 	pb "github.com/bin-x/websocket/proto"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
 	"reflect"
 	"testing"
+	"time"
 )
 
 type testApp struct {
@@ -25,21 +27,26 @@ var uid2 = "uid2"
 
 func CreateHub() *ServiceHub {
 	hub := &ServiceHub{
-		clients:       make(map[string]*Client),
-		connect:       make(chan *Client),
-		close:         make(chan *Client),
-		otherServices: make(map[string]*serviceRpcClient),
-		addServices:   make(chan map[string]*serviceRpcClient),
-		deleteService: make(chan string),
-		uidClients:    make(map[string]map[*Client]bool),
-		groups:        make(map[string]map[*Client]bool),
-		disbandGroup:  make(chan string),
-		joinGroup:     make(chan map[*Client]string),
-		leaveGroup:    make(chan map[*Client]string),
-		bindUid:       make(chan map[*Client]string),
-		unbindUid:     make(chan *Client),
-		otherAddress:  make(map[string]bool),
-		application:   &testApp{},
+		clients:        make(map[string]*Client),
+		connect:        make(chan *Client),
+		close:          make(chan *Client),
+		otherServices:  make(map[string]*serviceRpcClient),
+		addServices:    make(chan map[string]*serviceRpcClient),
+		deleteService:  make(chan string),
+		resume:         make(chan *resumeRequest),
+		getClients:     make(chan chan []*Client),
+		getClient:      make(chan *clientRequest),
+		groupMessages:  make(chan *groupMessage),
+		uidClients:     make(map[string]map[*Client]bool),
+		groups:         make(map[string]map[*Client]bool),
+		disbandGroup:   make(chan string),
+		joinGroup:      make(chan map[*Client]string),
+		leaveGroup:     make(chan map[*Client]string),
+		leaveAllGroups: make(chan *Client),
+		bindUid:        make(chan map[*Client]string),
+		unbindUid:      make(chan *Client),
+		otherAddress:   make(map[string]bool),
+		application:    &testApp{},
 	}
 
 	client1 := &Client{
@@ -48,11 +55,12 @@ func CreateHub() *ServiceHub {
 		uid:        uid1,
 		groups:     map[string]bool{groupString: true},
 		info:       map[string]string{"age": "11"},
+		send:       make(chan []byte, 256),
 		joinGroup:  make(chan string),
 		leaveGroup: make(chan string),
-		setInfo:    make(chan map[string]string),
-		updateInfo: make(chan map[string]string),
-		done:       make(chan bool),
+		infoOps:    make(chan *infoOp),
+		done:       make(chan CloseEvent),
+		getSession: make(chan chan *pb.Client),
 	}
 	client2 := &Client{
 		hub:        hub,
@@ -60,11 +68,12 @@ func CreateHub() *ServiceHub {
 		uid:        uid2,
 		groups:     map[string]bool{},
 		info:       map[string]string{"age": "21"},
+		send:       make(chan []byte, 256),
 		joinGroup:  make(chan string),
 		leaveGroup: make(chan string),
-		setInfo:    make(chan map[string]string),
-		updateInfo: make(chan map[string]string),
-		done:       make(chan bool),
+		infoOps:    make(chan *infoOp),
+		done:       make(chan CloseEvent),
+		getSession: make(chan chan *pb.Client),
 	}
 
 	hub.clients = map[string]*Client{"1": client1, "2": client2}
@@ -76,6 +85,20 @@ func CreateHub() *ServiceHub {
 	return hub
 }
 
+// 等待hub的run处理完之前发送的消息，getClients没有副作用
+func waitHub(hub *ServiceHub) {
+	reply := make(chan []*Client)
+	hub.getClients <- reply
+	<-reply
+}
+
+// 等待client的run处理完之前发送的消息，getSession没有副作用
+func waitClient(client *Client) {
+	reply := make(chan *pb.Client)
+	client.getSession <- reply
+	<-reply
+}
+
 func Test_rpcMethods_BindUid(t *testing.T) {
 	t.Parallel()
 
@@ -481,3 +504,513 @@ func Test_rpcMethods_IsUidOnline(t *testing.T) {
 //
 //func Test_rpcMethods_UpdateInfo(t *testing.T) {
 //}
+
+func Test_rpcMethods_DisbandGroup(t *testing.T) {
+	t.Parallel()
+	rm := &rpcMethods{
+		hub: CreateHub(),
+	}
+	client := rm.hub.clients["1"]
+	rm.DisbandGroup(context.Background(), &pb.ServiceRequest{Group: groupString})
+	waitHub(rm.hub)
+	waitClient(client)
+
+	if _, ok := rm.hub.groups[groupString]; ok {
+		t.Errorf("DisbandGroup() hub.groups not delete this group")
+	}
+	if _, ok := client.groups[groupString]; ok {
+		t.Errorf("DisbandGroup() client's groups not delete this group")
+	}
+}
+
+func Test_rpcMethods_GetGroupsByClientId(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		request *pb.ServiceRequest
+		want    []string
+	}{
+		{
+			request: &pb.ServiceRequest{ClientId: "1"},
+			want:    []string{groupString},
+		},
+		{
+			request: &pb.ServiceRequest{ClientId: "2"},
+			want:    []string{},
+		},
+		{
+			request: &pb.ServiceRequest{ClientId: "notexist"},
+			want:    []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		rm := &rpcMethods{
+			hub: CreateHub(),
+		}
+		response, err := rm.GetGroupsByClientId(context.Background(), tt.request)
+		if err != nil {
+			t.Errorf("GetGroupsByClientId() error = %v", err)
+			return
+		}
+
+		if !EqualWithoutIndex(response.Groups, tt.want) {
+			t.Errorf("GetGroupsByClientId() got = %v, want %v", response.Groups, tt.want)
+		}
+	}
+}
+
+func Test_rpcMethods_GetGroupsByUid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		request *pb.ServiceRequest
+		want    []string
+	}{
+		{
+			request: &pb.ServiceRequest{Uid: uid1},
+			want:    []string{groupString},
+		},
+		{
+			request: &pb.ServiceRequest{Uid: uid2},
+			want:    []string{},
+		},
+		{
+			request: &pb.ServiceRequest{Uid: "notexist"},
+			want:    []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		rm := &rpcMethods{
+			hub: CreateHub(),
+		}
+		response, err := rm.GetGroupsByUid(context.Background(), tt.request)
+		if err != nil {
+			t.Errorf("GetGroupsByUid() error = %v", err)
+			return
+		}
+
+		if !EqualWithoutIndex(response.Groups, tt.want) {
+			t.Errorf("GetGroupsByUid() got = %v, want %v", response.Groups, tt.want)
+		}
+	}
+}
+
+func Test_rpcMethods_JoinGroupByUid(t *testing.T) {
+	t.Parallel()
+	newGroup := "newgroup"
+	rm := &rpcMethods{
+		hub: CreateHub(),
+	}
+	client := rm.hub.clients["2"]
+	rm.JoinGroupByUid(context.Background(), &pb.ServiceRequest{Uid: uid2, Group: newGroup})
+	waitHub(rm.hub)
+	waitClient(client)
+
+	if _, ok := rm.hub.groups[newGroup][client]; !ok {
+		t.Errorf("JoinGroupByUid() hub.groups not contain this client")
+	}
+	if _, ok := client.groups[newGroup]; !ok {
+		t.Errorf("JoinGroupByUid() client's groups not contain this group")
+	}
+	if _, ok := rm.hub.groups[newGroup][rm.hub.clients["1"]]; ok {
+		t.Errorf("JoinGroupByUid() hub.groups contain client of other uid")
+	}
+}
+
+func Test_rpcMethods_LeaveAllGroups(t *testing.T) {
+	t.Parallel()
+	rm := &rpcMethods{
+		hub: CreateHub(),
+	}
+	client := rm.hub.clients["1"]
+	rm.LeaveAllGroups(context.Background(), &pb.ServiceRequest{ClientId: "1"})
+	waitHub(rm.hub)
+	waitClient(client)
+
+	if len(client.groups) != 0 {
+		t.Errorf("LeaveAllGroups() client's groups got = %v, want empty", client.groups)
+	}
+	if _, ok := rm.hub.groups[groupString]; ok {
+		t.Errorf("LeaveAllGroups() hub.groups not delete the empty group")
+	}
+}
+
+func Test_rpcMethods_SendToAll_Exclude(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		request *pb.ServiceRequest
+		want    map[string]int
+	}{
+		{
+			request: &pb.ServiceRequest{Message: []byte("hi")},
+			want:    map[string]int{"1": 1, "2": 1},
+		},
+		{
+			request: &pb.ServiceRequest{Message: []byte("hi"), ExcludeClientIds: []string{"1"}},
+			want:    map[string]int{"1": 0, "2": 1},
+		},
+		{
+			request: &pb.ServiceRequest{Message: []byte("hi"), ExcludeUids: []string{uid2}},
+			want:    map[string]int{"1": 1, "2": 0},
+		},
+	}
+
+	for _, tt := range tests {
+		rm := &rpcMethods{
+			hub: CreateHub(),
+		}
+		_, err := rm.SendToAll(context.Background(), tt.request)
+		if err != nil {
+			t.Errorf("SendToAll() error = %v", err)
+			return
+		}
+		for clientId, want := range tt.want {
+			if got := len(rm.hub.clients[clientId].send); got != want {
+				t.Errorf("SendToAll() client %v got %v messages, want %v", clientId, got, want)
+			}
+		}
+	}
+}
+
+func Test_rpcMethods_SendToGroups(t *testing.T) {
+	t.Parallel()
+	rm := &rpcMethods{
+		hub: CreateHub(),
+	}
+	client1 := rm.hub.clients["1"]
+	client2 := rm.hub.clients["2"]
+	rm.hub.groups["other"] = map[*Client]bool{client1: true, client2: true}
+
+	request := &pb.ServiceRequest{Message: []byte("hi"), Groups: []string{groupString, "other"}}
+	_, err := rm.SendToGroups(context.Background(), request)
+	if err != nil {
+		t.Errorf("SendToGroups() error = %v", err)
+		return
+	}
+	if len(client1.send) != 1 || len(client2.send) != 1 {
+		t.Errorf("SendToGroups() got %v and %v messages, want 1 and 1", len(client1.send), len(client2.send))
+	}
+}
+
+func Test_rpcMethods_GetClientSession(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		request *pb.ServiceRequest
+		want    []*pb.Client
+	}{
+		{
+			request: &pb.ServiceRequest{ClientId: "1"},
+			want: []*pb.Client{{
+				Id:    "1",
+				Uid:   uid1,
+				Group: []string{groupString},
+				Info:  map[string]string{"age": "11"},
+			}},
+		},
+		{
+			request: &pb.ServiceRequest{ClientId: "notexist"},
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		rm := &rpcMethods{
+			hub: CreateHub(),
+		}
+		response, err := rm.GetClientSession(context.Background(), tt.request)
+		if err != nil {
+			t.Errorf("GetClientSession() error = %v", err)
+			return
+		}
+		for _, client := range tt.want {
+			client.Node = rm.hub.rpcAddr()
+			client.ConnectTime = time.Time{}.Unix()
+		}
+
+		if !reflect.DeepEqual(response.Clients, tt.want) {
+			t.Errorf("GetClientSession() got = %v, want %v", response.Clients, tt.want)
+		}
+	}
+}
+
+func Test_rpcMethods_GetSessionsByUid(t *testing.T) {
+	t.Parallel()
+	rm := &rpcMethods{
+		hub: CreateHub(),
+	}
+	response, err := rm.GetSessionsByUid(context.Background(), &pb.ServiceRequest{Uid: uid2})
+	if err != nil {
+		t.Errorf("GetSessionsByUid() error = %v", err)
+		return
+	}
+	if len(response.Clients) != 1 || response.Clients[0].Id != "2" || response.Clients[0].Uid != uid2 {
+		t.Errorf("GetSessionsByUid() got = %v, want client 2", response.Clients)
+	}
+}
+
+func Test_rpcMethods_QueryClients(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		query *Query
+		index bool
+		want  []string
+	}{
+		{
+			query: NewQuery().InfoEq("age", "11"),
+			want:  []string{"1"},
+		},
+		{
+			query: NewQuery().InfoEq("age", "11"),
+			index: true,
+			want:  []string{"1"},
+		},
+		{
+			query: NewQuery().InfoIn("age", "11", "21"),
+			index: true,
+			want:  []string{"1", "2"},
+		},
+		{
+			query: NewQuery().InfoPrefix("age", "2"),
+			want:  []string{"2"},
+		},
+		{
+			query: NewQuery().UidPrefix("uid").InGroup(groupString),
+			want:  []string{"1"},
+		},
+		{
+			query: NewQuery().UidIn(uid1, uid2).InfoEq("age", "21"),
+			want:  []string{"2"},
+		},
+		{
+			query: NewQuery().InfoEq("notexist", "11"),
+			want:  []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		rm := &rpcMethods{
+			hub: CreateHub(),
+		}
+		if tt.index {
+			rm.hub.infoIndex = newInfoIndex()
+			rm.hub.infoIndex.keys["age"] = true
+			for _, client := range rm.hub.clients {
+				rm.hub.infoIndex.update(client, client.info)
+			}
+		}
+		response, err := rm.QueryClients(context.Background(), &pb.ServiceRequest{Predicates: tt.query.predicates})
+		if err != nil {
+			t.Errorf("QueryClients() error = %v", err)
+			return
+		}
+		if !EqualWithoutIndex(response.ClientIds, tt.want) || int(response.Count) != len(tt.want) {
+			t.Errorf("QueryClients() got = %v, want %v", response.ClientIds, tt.want)
+		}
+	}
+}
+
+func Test_rpcMethods_CompareAndSetInfo(t *testing.T) {
+	t.Parallel()
+	rm := &rpcMethods{
+		hub: CreateHub(),
+	}
+	client := rm.hub.clients["1"]
+
+	response, _ := rm.UpdateInfo(context.Background(), &pb.ServiceRequest{ClientId: "1", Info: map[string]string{"room": "x"}})
+	if !response.Success || response.InfoVersion != 1 {
+		t.Errorf("UpdateInfo() got version %v success %v, want 1 true", response.InfoVersion, response.Success)
+	}
+
+	response, _ = rm.CompareAndSetInfo(context.Background(), &pb.ServiceRequest{ClientId: "1", InfoVersion: 0, Info: map[string]string{"room": "y"}})
+	if !response.Conflict || response.InfoVersion != 1 {
+		t.Errorf("CompareAndSetInfo() with old version got conflict %v version %v, want true 1", response.Conflict, response.InfoVersion)
+	}
+
+	response, _ = rm.CompareAndSetInfo(context.Background(), &pb.ServiceRequest{ClientId: "1", InfoVersion: 1, Info: map[string]string{"room": "y"}})
+	if response.Conflict || response.InfoVersion != 2 {
+		t.Errorf("CompareAndSetInfo() got conflict %v version %v, want false 2", response.Conflict, response.InfoVersion)
+	}
+	want := map[string]string{"room": "y"}
+	if !reflect.DeepEqual(client.info, want) {
+		t.Errorf("CompareAndSetInfo() info got = %v, want %v", client.info, want)
+	}
+
+	response, _ = rm.SetInfo(context.Background(), &pb.ServiceRequest{ClientId: "notexist"})
+	if response.Success {
+		t.Errorf("SetInfo() on not exist client got success")
+	}
+}
+
+func Test_rpcMethods_DeleteInfoKeys(t *testing.T) {
+	t.Parallel()
+	rm := &rpcMethods{
+		hub: CreateHub(),
+	}
+	client := rm.hub.clients["2"]
+	rm.UpdateInfo(context.Background(), &pb.ServiceRequest{ClientId: "2", Info: map[string]string{"room": "x", "platform": "ios"}})
+	response, _ := rm.DeleteInfoKeys(context.Background(), &pb.ServiceRequest{ClientId: "2", InfoKeys: []string{"room", "age"}})
+
+	want := map[string]string{"platform": "ios"}
+	if !reflect.DeepEqual(client.info, want) || response.InfoVersion != 2 {
+		t.Errorf("DeleteInfoKeys() got = %v version %v, want %v version 2", client.info, response.InfoVersion, want)
+	}
+}
+
+func Test_rpcMethods_applyInfoOpClosed(t *testing.T) {
+	t.Parallel()
+	rm := &rpcMethods{
+		hub: CreateHub(),
+	}
+	// 已关闭的client不再读取infoOps
+	closed := &Client{hub: rm.hub, id: "3", infoOps: make(chan *infoOp), closed: make(chan struct{})}
+	close(closed.closed)
+	rm.hub.connect <- closed
+
+	for _, clientId := range []string{"3", "4"} {
+		response, _ := rm.CompareAndSetInfo(context.Background(), &pb.ServiceRequest{ClientId: clientId, InfoVersion: 0, Info: map[string]string{"room": "x"}})
+		if response.Success {
+			t.Errorf("CompareAndSetInfo() on client %v got success", clientId)
+		}
+	}
+}
+
+func TestInfoValue(t *testing.T) {
+	t.Parallel()
+	type device struct {
+		Platform string `json:"platform"`
+		Version  int    `json:"version"`
+	}
+	raw, err := EncodeInfoValue(device{Platform: "ios", Version: 4})
+	if err != nil {
+		t.Errorf("EncodeInfoValue() error = %v", err)
+		return
+	}
+	var got device
+	if err := DecodeInfoValue(raw, &got); err != nil || got.Version != 4 {
+		t.Errorf("DecodeInfoValue() got = %v, err = %v", got, err)
+	}
+
+	raw, _ = EncodeInfoValue("room")
+	var s string
+	if DecodeInfoValue(raw, &s); raw != "room" || s != "room" {
+		t.Errorf("string info value got raw = %v, decoded = %v, want room", raw, s)
+	}
+}
+
+func Test_rpcMethods_DeliverResponse(t *testing.T) {
+	t.Parallel()
+	rm := &rpcMethods{
+		hub: CreateHub(),
+	}
+	rm.hub.lanIp = "127.0.0.1"
+	rm.hub.rpcPort = 9101
+	rm.hub.requests = newPendingRequests()
+	rm.hub.requestRoutes = newRequestRoutes()
+	local := metadata.NewIncomingContext(context.Background(), metadata.Pairs(metadataCallerKey, rm.hub.rpcAddr()))
+
+	// 本服务发起的请求，回复来自本服务的client
+	id := rm.hub.newRequestId()
+	reply := rm.hub.requests.add(id, "2")
+	if _, err := rm.SendRequest(context.Background(), &pb.ServiceRequest{ClientId: "2", RequestId: id, Message: []byte("request")}); err == nil {
+		t.Errorf("SendRequest() without caller got err = nil")
+	}
+	response, err := rm.SendRequest(local, &pb.ServiceRequest{ClientId: "2", RequestId: id, Message: []byte("request")})
+	if err != nil || !response.Success {
+		t.Errorf("SendRequest() got = %v, err = %v", response, err)
+	}
+	if response, _ := rm.SendRequest(local, &pb.ServiceRequest{ClientId: "404", RequestId: id}); response.Success {
+		t.Errorf("SendRequest() to unknown client got success")
+	}
+	message := []byte(`{"type":"response","id":"` + id + `","data":{"ok":true}}`)
+	// 其他client的回复和未知id的回复被丢弃
+	if !rm.hub.handleResponse("1", message) {
+		t.Errorf("handleResponse() from other client got = false, want true")
+	}
+	if !rm.hub.handleResponse("2", []byte(`{"type":"response","id":"unknown","data":{}}`)) {
+		t.Errorf("handleResponse() with unknown id got = false, want true")
+	}
+	select {
+	case envelope := <-reply:
+		t.Errorf("handleResponse() resolved by wrong response, got = %v", envelope)
+	default:
+	}
+	if !rm.hub.handleResponse("2", message) {
+		t.Errorf("handleResponse() got = false, want true")
+	}
+	if envelope := <-reply; string(envelope.Data) != `{"ok":true}` {
+		t.Errorf("handleResponse() data got = %s", envelope.Data)
+	}
+	// 只能回复一次
+	if _, ok := rm.hub.requestRoutes.take(id, "2"); ok {
+		t.Errorf("requestRoutes.take() got route after response")
+	}
+
+	// 其他服务发起的请求，转发给记录的服务
+	id = "remote-request"
+	remote := metadata.NewIncomingContext(context.Background(), metadata.Pairs(metadataCallerKey, "10.0.0.2:9101"))
+	rm.SendRequest(remote, &pb.ServiceRequest{ClientId: "2", RequestId: id, Message: []byte("request")})
+	if route, ok := rm.hub.requestRoutes.take(id, "2"); !ok || route.origin != "10.0.0.2:9101" {
+		t.Errorf("requestRoutes.take() got = %v, %v", route, ok)
+	}
+
+	// 回复由其他服务转发，只接受请求的client的回复
+	id = rm.hub.newRequestId()
+	reply = rm.hub.requests.add(id, "2")
+	message = []byte(`{"type":"response","id":"` + id + `","error":{"code":"rejected","message":"no"}}`)
+	if response, _ := rm.DeliverResponse(context.Background(), &pb.ServiceRequest{RequestId: id, ClientId: "1", Message: message}); response.Success {
+		t.Errorf("DeliverResponse() from other client got success")
+	}
+	rm.DeliverResponse(context.Background(), &pb.ServiceRequest{RequestId: id, ClientId: "2", Message: message})
+	if envelope := <-reply; envelope.Error == nil || envelope.Error.Code != "rejected" {
+		t.Errorf("DeliverResponse() error got = %v", envelope.Error)
+	}
+
+	if rm.hub.handleResponse("2", []byte(`{"type":"chat"}`)) {
+		t.Errorf("handleResponse() handled a normal message")
+	}
+}
+
+func Test_rpcMethods_JoinGroup_Replay(t *testing.T) {
+	t.Parallel()
+	rm := &rpcMethods{
+		hub: CreateHub(),
+	}
+	WithGroupHistory(2, nil)(rm.hub)
+	for _, message := range []string{"1", "2", "3"} {
+		rm.SendToGroup(context.Background(), &pb.ServiceRequest{Group: groupString, Message: []byte(message)})
+	}
+
+	client := rm.hub.clients["2"]
+	rm.JoinGroup(context.Background(), &pb.ServiceRequest{ClientId: "2", Group: groupString, Replay: newReplay([]ReplayOption{ReplayLast(1)})})
+	waitHub(rm.hub)
+	if len(client.send) != 1 || string(<-client.send) != "3" {
+		t.Errorf("JoinGroup() with ReplayLast(1) didn't send the last message")
+	}
+
+	tests := []struct {
+		replay []ReplayOption
+		want   []uint64
+	}{
+		{
+			replay: nil,
+			want:   []uint64{2, 3},
+		},
+		{
+			replay: []ReplayOption{ReplaySinceSeq(2)},
+			want:   []uint64{3},
+		},
+		{
+			replay: []ReplayOption{ReplaySince(time.Now().Add(time.Minute))},
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		var got []uint64
+		for _, message := range rm.hub.history.query(groupString, newReplay(tt.replay)) {
+			got = append(got, message.Seq)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("history.query() got = %v, want %v", got, tt.want)
+		}
+	}
+}